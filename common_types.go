@@ -25,6 +25,8 @@ const (
 	CommonNameDateTime commonName = "dateTime"
 	// CommonNamePassword data type is string, format password
 	CommonNamePassword commonName = "password"
+	// CommonNameDecimal data type is string, format decimal (decimal number, e.g. amount of money)
+	CommonNameDecimal commonName = "decimal"
 )
 
 type typeFormat struct {
@@ -44,6 +46,7 @@ var commonNamesMap = map[commonName]typeFormat{
 	CommonNameDate:     {"string", "date"},
 	CommonNameDateTime: {"string", "date-time"},
 	CommonNamePassword: {"string", "password"},
+	CommonNameDecimal:  {"string", "decimal"},
 }
 
 func isCommonName(typeName string) (ok bool) {
@@ -66,3 +69,11 @@ func SchemaFromCommonName(name commonName) SchemaObj {
 		Type: string(name),
 	}
 }
+
+// schemaFromCommonName is SchemaFromCommonName that also looks up names registered with RegisterCommonName
+func (g *Generator) schemaFromCommonName(name string) SchemaObj {
+	if schema, ok := g.commonNames[name]; ok {
+		return schema
+	}
+	return SchemaFromCommonName(commonName(name))
+}
@@ -1,6 +1,7 @@
 package swgen
 
 import (
+	"encoding/json"
 	"testing"
 )
 
@@ -15,3 +16,74 @@ func TestDefinition(t *testing.T) {
 	assertTrue(typeName == "MyName", t)
 	assertTrue(err == nil, t)
 }
+
+type hostnames []string
+
+func (hostnames) SwgenDefinition() (typeName string, typeDef SchemaObj, err error) {
+	maxItems := 10
+	return "Hostnames", SchemaObj{
+		Type:     "array",
+		Items:    &SchemaObj{Type: "string", Format: "hostname"},
+		MaxItems: &maxItems,
+	}, nil
+}
+
+func TestDefinitionArrayRoot(t *testing.T) {
+	type Cluster struct {
+		Hosts    hostnames   `json:"hosts"`
+		Backups  *hostnames  `json:"backups"`
+		Replicas []hostnames `json:"replicas"`
+	}
+
+	g := NewGenerator()
+	schema, err := g.ParseDefinition(hostnames{})
+	assertTrue(err == nil, t)
+	assertTrue(schema.Ref == "#/definitions/Hostnames", t)
+
+	_, err = g.ParseDefinition(Cluster{})
+	assertTrue(err == nil, t)
+
+	definitions := g.Definitions()
+	data, err := json.Marshal(definitions["Hostnames"])
+	assertTrue(err == nil, t)
+	if string(data) != `{"type":"array","items":{"type":"string","format":"hostname"},"maxItems":10}` {
+		t.Fatalf("unexpected definition %s", data)
+	}
+
+	cluster := definitions["Cluster"]
+	assertTrue(cluster.Properties["hosts"].Ref == "#/definitions/Hostnames", t)
+	assertTrue(cluster.Properties["backups"].Ref == "#/definitions/Hostnames", t)
+	assertTrue(cluster.Properties["replicas"].Items.Ref == "#/definitions/Hostnames", t)
+}
+
+type versionedUser struct {
+	Name string `json:"name"`
+}
+
+func (versionedUser) SwgenDefinition() (typeName string, typeDef SchemaObj, err error) {
+	return "v1/User~Legacy", SchemaObj{Type: "object", Properties: map[string]SchemaObj{"name": {Type: "string"}}}, nil
+}
+
+func TestDefinitionNameEscaping(t *testing.T) {
+	type Team struct {
+		Lead    versionedUser   `json:"lead"`
+		Members []versionedUser `json:"members"`
+	}
+
+	g := NewGenerator()
+	_, err := g.ParseDefinition(Team{})
+	assertTrue(err == nil, t)
+
+	schema, err := g.ParseDefinition(versionedUser{})
+	assertTrue(err == nil, t)
+	assertTrue(schema.Ref == "#/definitions/v1_User_Legacy", t)
+
+	definitions := g.Definitions()
+	_, found := definitions["v1_User_Legacy"]
+	assertTrue(found, t)
+
+	team := definitions["Team"]
+	assertTrue(team.Properties["lead"].Ref == "#/definitions/v1_User_Legacy", t)
+	assertTrue(team.Properties["members"].Items.Ref == "#/definitions/v1_User_Legacy", t)
+	assertTrue(len(g.Validate()) == 0, t)
+}
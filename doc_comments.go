@@ -0,0 +1,105 @@
+package swgen
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// LoadDocComments reads Go doc comments of struct types and their fields from sources of package
+// with importPath in dir, test files are skipped, comments are used as descriptions of definitions
+// and properties that are parsed afterwards
+func (g *Generator) LoadDocComments(dir, importPath string) error {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+	}
+
+	g.mu.Lock()
+	if g.docComments == nil {
+		g.docComments = make(map[string]string)
+	}
+	for _, file := range files {
+		g.addFileDocComments(importPath, file)
+	}
+	g.mu.Unlock()
+	g.invalidateCache()
+
+	return nil
+}
+
+// addFileDocComments adds doc comments of struct types declared in file by importPath.Type
+// and importPath.Type.Field names
+func (g *Generator) addFileDocComments(importPath string, file *ast.File) {
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			typeKey := importPath + "." + typeSpec.Name.Name
+
+			doc := typeSpec.Doc
+			if doc == nil && len(genDecl.Specs) == 1 {
+				doc = genDecl.Doc
+			}
+			g.addDocComment(typeKey, doc)
+
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+
+			for _, field := range structType.Fields.List {
+				doc := field.Doc
+				if doc == nil {
+					doc = field.Comment
+				}
+				for _, name := range field.Names {
+					g.addDocComment(typeKey+"."+name.Name, doc)
+				}
+			}
+		}
+	}
+}
+
+func (g *Generator) addDocComment(key string, doc *ast.CommentGroup) {
+	if text := strings.TrimSpace(doc.Text()); text != "" {
+		g.docComments[key] = text
+	}
+}
+
+// docComment returns loaded doc comment of named type t or of its field if field is not empty
+func (g *Generator) docComment(t reflect.Type, field string) (doc string, found bool) {
+	if g.docComments == nil || t.Name() == "" {
+		return "", false
+	}
+
+	key := importPath(t) + "." + t.Name()
+	if field != "" {
+		key += "." + field
+	}
+	doc, found = g.docComments[key]
+	return doc, found
+}
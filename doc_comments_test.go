@@ -0,0 +1,78 @@
+package swgen
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/lazada/swgen/sample"
+)
+
+func TestLoadDocComments(t *testing.T) {
+	g := NewGenerator()
+	if err := g.LoadDocComments("sample", "github.com/lazada/swgen/sample"); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if _, err := g.ParseDefinition(sample.TestSampleStruct{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(sample.TestSampleStruct{}))
+	if typeDef.Description != "TestSampleStruct is a sample structure living in a separate package." {
+		t.Fatalf("unexpected description %q", typeDef.Description)
+	}
+	if desc := typeDef.Properties["simple_float64"].Description; desc != "SimpleFloat64 is a floating point value." {
+		t.Fatalf("unexpected simple_float64 description %q", desc)
+	}
+	if desc := typeDef.Properties["simple_bool"].Description; desc != "flag value" {
+		t.Fatalf("unexpected simple_bool description %q", desc)
+	}
+}
+
+func TestLoadDocCommentsMissingDir(t *testing.T) {
+	if err := NewGenerator().LoadDocComments("not-existing", "not-existing"); err == nil {
+		t.Fatal("error expected")
+	}
+}
+
+type docCommentsUser struct {
+	Name string `json:"name"`
+}
+
+func TestLoadDocCommentsSkipsTestFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "swgen-doc")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"user.go":      "package swgen\n\n// docCommentsUser is a user.\ntype docCommentsUser struct {\n\tName string // user name\n}\n",
+		"user_test.go": "package swgen\n\n// docCommentsUser is a test user.\ntype docCommentsUser struct {\n\tName string // test name\n}\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+
+	g := NewGenerator()
+	if err := g.LoadDocComments(dir, "github.com/lazada/swgen"); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if _, err := g.ParseDefinition(docCommentsUser{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(docCommentsUser{}))
+	if typeDef.Description != "docCommentsUser is a user." {
+		t.Fatalf("unexpected description %q", typeDef.Description)
+	}
+	if desc := typeDef.Properties["name"].Description; desc != "user name" {
+		t.Fatalf("unexpected name description %q", desc)
+	}
+}
@@ -22,13 +22,43 @@ type Document struct {
 	Info                InfoObj                `json:"info"`                          // Provides metadata about the API
 	Host                string                 `json:"host,omitempty"`                // The host (name or ip) serving the API
 	BasePath            string                 `json:"basePath"`                      // The base path on which the API is served, which is relative to the host
-	Schemes             []string               `json:"schemes"`                       // Values MUST be from the list: "http", "https", "ws", "wss"
+	Schemes             []string               `json:"schemes,omitempty"`             // Values MUST be from the list: "http", "https", "ws", "wss"
 	Paths               map[string]PathItem    `json:"paths"`                         // The available paths and operations for the API
-	Definitions         map[string]SchemaObj   `json:"definitions"`                   // An object to hold data types produced and consumed by operations
+	Definitions         map[string]SchemaObj   `json:"definitions,omitempty"`         // An object to hold data types produced and consumed by operations
+	Parameters          map[string]ParamObj    `json:"parameters,omitempty"`          // An object to hold parameters that can be used across operations
+	Responses           map[string]ResponseObj `json:"responses,omitempty"`           // An object to hold responses that can be used across operations
 	SecurityDefinitions map[string]SecurityDef `json:"securityDefinitions,omitempty"` // An object to hold available security mechanisms
 	additionalData
 }
 
+// clone returns a copy of Document with copied maps of paths and shared objects
+func (s Document) clone() Document {
+	c := s
+	c.Schemes = append([]string(nil), s.Schemes...)
+	c.Paths = make(map[string]PathItem, len(s.Paths))
+	for path, item := range s.Paths {
+		c.Paths[path] = item.clone()
+	}
+	c.Definitions = make(map[string]SchemaObj, len(s.Definitions))
+	for name, def := range s.Definitions {
+		c.Definitions[name] = def
+	}
+	c.Parameters = make(map[string]ParamObj, len(s.Parameters))
+	for name, param := range s.Parameters {
+		c.Parameters[name] = param
+	}
+	c.Responses = make(map[string]ResponseObj, len(s.Responses))
+	for name, res := range s.Responses {
+		c.Responses[name] = res
+	}
+	c.SecurityDefinitions = make(map[string]SecurityDef, len(s.SecurityDefinitions))
+	for name, def := range s.SecurityDefinitions {
+		c.SecurityDefinitions[name] = def
+	}
+	c.additionalData = s.additionalData.clone()
+	return c
+}
+
 type _Document Document
 
 // MarshalJSON marshal Document with additionalData inlined
@@ -95,6 +125,64 @@ func (pi PathItem) HasMethod(method string) bool {
 	return false
 }
 
+// clone returns a copy of PathItem with copied operations
+func (pi PathItem) clone() PathItem {
+	for _, op := range []**OperationObj{&pi.Get, &pi.Put, &pi.Post, &pi.Delete, &pi.Options, &pi.Head, &pi.Patch} {
+		if *op != nil {
+			*op = (*op).clone()
+		}
+	}
+	return pi
+}
+
+// operation returns operation of PathItem by HTTP method
+func (pi PathItem) operation(method string) *OperationObj {
+	switch strings.ToUpper(method) {
+	case "GET":
+		return pi.Get
+	case "POST":
+		return pi.Post
+	case "PUT":
+		return pi.Put
+	case "DELETE":
+		return pi.Delete
+	case "OPTIONS":
+		return pi.Options
+	case "HEAD":
+		return pi.Head
+	case "PATCH":
+		return pi.Patch
+	}
+
+	return nil
+}
+
+// setOperation sets operation of PathItem by HTTP method, nil op removes the operation
+func (pi *PathItem) setOperation(method string, op *OperationObj) {
+	switch strings.ToUpper(method) {
+	case "GET":
+		pi.Get = op
+	case "POST":
+		pi.Post = op
+	case "PUT":
+		pi.Put = op
+	case "DELETE":
+		pi.Delete = op
+	case "OPTIONS":
+		pi.Options = op
+	case "HEAD":
+		pi.Head = op
+	case "PATCH":
+		pi.Patch = op
+	}
+}
+
+// isEmpty checks if PathItem has no operations
+func (pi PathItem) isEmpty() bool {
+	return pi.Get == nil && pi.Put == nil && pi.Post == nil && pi.Delete == nil &&
+		pi.Options == nil && pi.Head == nil && pi.Patch == nil
+}
+
 type securityType string
 
 const (
@@ -128,6 +216,12 @@ const (
 	Oauth2Password oauthFlow = "password"
 )
 
+// TagGroup groups tags of operations in UI, e.g. ReDoc, it is added to document with Generator.AddTagGroup
+type TagGroup struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
 // SecurityDef holds security definition
 type SecurityDef struct {
 	Type securityType `json:"type"`
@@ -151,10 +245,27 @@ type PathItemInfo struct {
 	Description string
 	Tag         string
 	Deprecated  bool
+	OperationID string // Unique identifier of operation
+	BodyForm    bool   // Fields of body are form parameters named by tags like in Generator.ParseParameter
+
+	SuccessStatus      int    // HTTP status code of the success response, 200 if not set
+	SuccessDescription string // Description of the success response, "request success" if not set
+	NoDefaultResponse  bool   // Skip success response without schema that is added when response is nil
+
+	Parameters   []string       // Names of global parameters used by operation, see Generator.AddGlobalParameter
+	ParameterSet string         // Name of parameters set added to operation, see Generator.RegisterParameterSet
+	Responses    map[int]string // Map of HTTP status codes to names of global responses, see Generator.AddGlobalResponse
+
+	// Parameters added as is after parsed ones, they replace parsed parameters with the same name and location
+	ExtraParameters []ParamObj
 
 	Security       []string            // Names of security definitions
 	SecurityOAuth2 map[string][]string // Map of names of security definitions to required scopes
 
+	// Alternative security requirements, each of them is a map of names of security definitions
+	// that are required together to scopes, Security and SecurityOAuth2 make one more requirement
+	SecurityRequirements []map[string][]string
+
 	additionalData
 }
 
@@ -172,16 +283,35 @@ type enumer interface {
 // OperationObj describes a single API operation on a path
 // see http://swagger.io/specification/#operationObject
 type OperationObj struct {
-	Tags        []string            `json:"tags,omitempty"`
-	Summary     string              `json:"summary"`     // like a title, a short summary of what the operation does (120 chars)
-	Description string              `json:"description"` // A verbose explanation of the operation behavior
-	Parameters  []ParamObj          `json:"parameters,omitempty"`
-	Responses   Responses           `json:"responses"`
-	Security    map[string][]string `json:"security,omitempty"`
-	Deprecated  bool                `json:"deprecated,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary"`     // like a title, a short summary of what the operation does (120 chars)
+	Description string                `json:"description"` // A verbose explanation of the operation behavior
+	OperationID string                `json:"operationId,omitempty"`
+	Consumes    []string              `json:"consumes,omitempty"`
+	Produces    []string              `json:"produces,omitempty"`
+	Parameters  []ParamObj            `json:"parameters,omitempty"`
+	Responses   Responses             `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
 	additionalData
 }
 
+// clone returns a copy of OperationObj with copied parameters and responses
+func (o *OperationObj) clone() *OperationObj {
+	c := *o
+	c.Tags = append([]string(nil), o.Tags...)
+	c.Consumes = append([]string(nil), o.Consumes...)
+	c.Produces = append([]string(nil), o.Produces...)
+	c.Parameters = append([]ParamObj(nil), o.Parameters...)
+	c.Security = append([]map[string][]string(nil), o.Security...)
+	c.Responses = make(Responses, len(o.Responses))
+	for status, res := range o.Responses {
+		c.Responses[status] = res
+	}
+	c.additionalData = o.additionalData.clone()
+	return &c
+}
+
 type _OperationObj OperationObj
 
 // MarshalJSON marshal OperationObj with additionalData inlined
@@ -197,20 +327,36 @@ type ParamObj struct {
 	In               string        `json:"in"` // Possible values are "query", "header", "path", "formData" or "body"
 	Type             string        `json:"type,omitempty"`
 	Format           string        `json:"format,omitempty"`
+	Pattern          string        `json:"pattern,omitempty"`
 	Items            *ParamItemObj `json:"items,omitempty"`            // Required if type is "array"
 	Schema           *SchemaObj    `json:"schema,omitempty"`           // Required if type is "body"
 	CollectionFormat string        `json:"collectionFormat,omitempty"` // "multi" - this is valid only for parameters in "query" or "formData"
+	MinItems         *int          `json:"minItems,omitempty"`
+	MaxItems         *int          `json:"maxItems,omitempty"`
+	UniqueItems      bool          `json:"uniqueItems,omitempty"`
+	MultipleOf       *float64      `json:"multipleOf,omitempty"`
+	Minimum          *float64      `json:"minimum,omitempty"`
+	ExclusiveMinimum bool          `json:"exclusiveMinimum,omitempty"`
+	Maximum          *float64      `json:"maximum,omitempty"`
+	ExclusiveMaximum bool          `json:"exclusiveMaximum,omitempty"`
 	Description      string        `json:"description,omitempty"`
 	Default          interface{}   `json:"default,omitempty"`
 	Required         bool          `json:"required,omitempty"`
+	AllowEmptyValue  bool          `json:"allowEmptyValue,omitempty"` // valid only for parameters in "query" or "formData"
+	Deprecated       bool          `json:"x-deprecated,omitempty"`    // Swagger 2.0 has no native deprecation of parameters
 	Enum
 	additionalData
 }
 
 type _ParamObj ParamObj
 
-// MarshalJSON marshal OperationObj with additionalData inlined
+// MarshalJSON marshal ParamObj with additionalData inlined, reference objects are marshaled with $ref only
 func (o ParamObj) MarshalJSON() ([]byte, error) {
+	if o.Ref != "" {
+		return json.Marshal(struct {
+			Ref string `json:"$ref"`
+		}{o.Ref})
+	}
 	return o.marshalJSONWithStruct(_ParamObj(o))
 }
 
@@ -241,16 +387,49 @@ type SchemaObj struct {
 	Ref                  string               `json:"$ref,omitempty"`
 	Description          string               `json:"description,omitempty"`
 	Default              interface{}          `json:"default,omitempty"`
+	Example              interface{}          `json:"example,omitempty"`
 	Type                 string               `json:"type,omitempty"`
 	Format               string               `json:"format,omitempty"`
+	Pattern              string               `json:"pattern,omitempty"` // if type is string
 	Title                string               `json:"title,omitempty"`
 	Items                *SchemaObj           `json:"items,omitempty"`                // if type is array
+	MinItems             *int                 `json:"minItems,omitempty"`             // if type is array
+	MaxItems             *int                 `json:"maxItems,omitempty"`             // if type is array
+	UniqueItems          bool                 `json:"uniqueItems,omitempty"`          // if type is array
+	MultipleOf           *float64             `json:"multipleOf,omitempty"`           // if type is integer or number
+	Minimum              *float64             `json:"minimum,omitempty"`              // if type is integer or number
+	ExclusiveMinimum     bool                 `json:"exclusiveMinimum,omitempty"`     // if minimum is set
+	Maximum              *float64             `json:"maximum,omitempty"`              // if type is integer or number
+	ExclusiveMaximum     bool                 `json:"exclusiveMaximum,omitempty"`     // if maximum is set
 	AdditionalProperties *SchemaObj           `json:"additionalProperties,omitempty"` // if type is object (map[])
 	Properties           map[string]SchemaObj `json:"properties,omitempty"`           // if type is object
-	TypeName             string               `json:"-"`                              // for internal using, passing typeName
+	OneOf                []SchemaObj          `json:"x-oneOf,omitempty"`              // implementations of interface types
+	AllOf                []SchemaObj          `json:"allOf,omitempty"`                // wraps reference to allow sibling keywords
+	Required             []string             `json:"required,omitempty"`             // if type is object
+	Discriminator        string               `json:"discriminator,omitempty"`        // name of property that selects polymorphic type
+	RequiredOneOf        [][]string           `json:"x-required-one-of,omitempty"`    // groups of properties where at least one is required
+	Enum                 []interface{}        `json:"enum,omitempty"`
+	EnumVarNames         []string             `json:"x-enum-varnames,omitempty"` // names of enum constants
+	Deprecated           bool                 `json:"x-deprecated,omitempty"`    // Swagger 2.0 has no native deprecation of schemas
+	Nullable             bool                 `json:"x-nullable,omitempty"`      // Swagger 2.0 has no native nullable schemas
+	TypeName             string               `json:"-"`                         // for internal using, passing typeName
 	GoType               string               `json:"x-go-type,omitempty"`
+	GoPackage            string               `json:"x-go-package,omitempty"`
 	GoPropertyNames      map[string]string    `json:"x-go-property-names,omitempty"`
 	GoPropertyTypes      map[string]string    `json:"x-go-property-types,omitempty"`
+	raw                  json.RawMessage      // hand-written schema marshaled as is, see Generator.AddRawDefinition
+	propertyOrder        int                  // x-order of next property, see Generator.SetEmitPropertyOrder
+	additionalData
+}
+
+type _SchemaObj SchemaObj
+
+// MarshalJSON marshal SchemaObj with additionalData inlined
+func (so SchemaObj) MarshalJSON() ([]byte, error) {
+	if so.raw != nil {
+		return so.raw, nil
+	}
+	return so.marshalJSONWithStruct(_SchemaObj(so))
 }
 
 // NewSchemaObj Constructor function for SchemaObj struct type
@@ -284,6 +463,22 @@ func (so *SchemaObj) isEmpty() bool {
 	}
 }
 
+// setDiscriminator sets discriminator property, which is required by specification
+func (so *SchemaObj) setDiscriminator(propertyName string) {
+	so.Discriminator = propertyName
+	so.addRequired(propertyName)
+}
+
+// addRequired adds property to required properties unless it is already there
+func (so *SchemaObj) addRequired(propertyName string) {
+	for _, name := range so.Required {
+		if name == propertyName {
+			return
+		}
+	}
+	so.Required = append(so.Required, propertyName)
+}
+
 // Export returns a "schema reference object" corresponding to this schema object. A "schema reference object" is an abridged
 // version of the original SchemaObj, having only two non-empty fields: Ref and TypeName. "Schema reference objects"
 // are used to refer original schema objects from other schemas.
@@ -294,10 +489,41 @@ func (so SchemaObj) Export() SchemaObj {
 	}
 }
 
+// IsRef checks whether schema is a reference to a definition
+func (so SchemaObj) IsRef() bool {
+	return so.Ref != ""
+}
+
+// IsInline checks whether schema is described in full in-place instead of referencing a definition
+func (so SchemaObj) IsInline() bool {
+	return so.Ref == ""
+}
+
+// RefName returns name of referenced definition, that is the last segment of reference, or empty string
+func (so SchemaObj) RefName() string {
+	if so.Ref == "" {
+		return ""
+	}
+	return so.Ref[strings.LastIndex(so.Ref, "/")+1:]
+}
+
 type additionalData struct {
 	data map[string]interface{}
 }
 
+// clone returns a copy of additional data
+func (ad additionalData) clone() additionalData {
+	if ad.data == nil {
+		return ad
+	}
+
+	data := make(map[string]interface{}, len(ad.data))
+	for name, value := range ad.data {
+		data[name] = value
+	}
+	return additionalData{data: data}
+}
+
 // AddExtendedField add field to additional data map
 func (ad *additionalData) AddExtendedField(name string, value interface{}) {
 	if ad.data == nil {
@@ -28,6 +28,24 @@ func TestAdditionalDataJSONMarshal(t *testing.T) {
 	assertTrue(string(data) == `{"x-custom-field":1}`, t)
 }
 
+func TestSchemaObjRef(t *testing.T) {
+	g := NewGenerator()
+
+	named, err := g.ParseDefinition(Person{})
+	assertTrue(err == nil, t)
+	assertTrue(named.IsRef(), t)
+	assertFalse(named.IsInline(), t)
+	assertTrue(named.RefName() == "Person", t)
+
+	anonymous, err := g.ParseDefinition(struct {
+		Name string `json:"name"`
+	}{})
+	assertTrue(err == nil, t)
+	assertFalse(anonymous.IsRef(), t)
+	assertTrue(anonymous.IsInline(), t)
+	assertTrue(anonymous.RefName() == "", t)
+}
+
 func assertTrue(v bool, t *testing.T) {
 	if v != true {
 		t.Fatal("value must return true")
@@ -1,9 +1,13 @@
 package swgen
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -20,11 +24,50 @@ type Generator struct {
 	definitionAdded map[string]bool           // index of TypeNames
 	definitions     defMap                    // list of all definition objects
 	defQueue        map[reflect.Type]struct{} // queue of reflect.Type objects waiting for analysis
+	defPaths        map[reflect.Type]string   // field paths queued types were first referenced by
+	parsePath       []string                  // path of the field being parsed
 	paths           map[string]PathItem       // list all of paths object
 	typesMap        map[reflect.Type]interface{}
+	interfaceImpls  map[reflect.Type][]reflect.Type // registered implementations of interface types
+	typeRefs        map[reflect.Type]string         // external references of types mapped with MapTypeToRef
+	paramSets       map[string][]ParamObj           // parameters registered with RegisterParameterSet by name
+	rawDefinitions  map[string]json.RawMessage      // definitions added with AddRawDefinition by name
+	typeFormats     map[reflect.Type]typeFormat     // types and formats of types mapped with MapTypeFormat
+	commonNames     map[string]SchemaObj            // schemas of swgen_type values registered with RegisterCommonName
+	definitionOpts  map[reflect.Type]*definitionOptions
 
-	indentJSON     bool
-	reflectGoTypes bool
+	refPrefix            string // prefix of definition references
+	duplicateNamePolicy  DuplicateNamePolicy
+	statusDescriptions   map[int]string    // descriptions of responses by HTTP status, merged over defaults
+	paramDescriptions    map[string]string // descriptions of parameters without description tag by field name
+	indentJSON           bool
+	reflectGoNames       bool
+	reflectGoTypes       bool
+	propertyNamer        func(field reflect.StructField) string
+	responseWrapper      func(inner SchemaObj) SchemaObj
+	fieldTag             string // tag with names of properties, json by default
+	allowBodyForGET      bool
+	formatHeuristics     bool
+	hoistAnonymous       bool
+	nullablePointers     bool
+	warnUnexportedTagged bool
+	strictParameters     bool
+	inferRequired        bool
+	emitPropertyOrder    bool
+	inlineSingleUse      bool
+	autoContentType      bool
+	intWidth             int                   // width in bits of int and uint types in schemas, 32 or 64
+	uuidTypes            map[reflect.Type]bool // types described as uuid strings by format heuristics
+	docComments          map[string]string     // Go doc comments by import/path.Type and import/path.Type.Field names
+
+	goTypesMu sync.Mutex              // mutex for goTypes
+	goTypes   map[reflect.Type]string // memoized results of goType
+
+	warnMu   sync.Mutex // mutex for warnings
+	warnings []string   // problems found while parsing that did not stop generation
+
+	cacheMu  sync.Mutex        // mutex for documents cache
+	docCache map[string][]byte // documents served with ServeHTTP by host, reset on changes
 
 	mu sync.Mutex // mutex for Generator's public API
 }
@@ -49,23 +92,69 @@ func (m *defMap) GenDefinitions() (result map[string]SchemaObj) {
 	return
 }
 
+// Options holds common settings of Generator applied with NewGeneratorWithOptions,
+// zero values keep defaults of NewGenerator
+type Options struct {
+	Host                string
+	BasePath            string   // "/" if empty
+	Schemes             []string // "http" and "https" if empty
+	RefPrefix           string   // "#/definitions/" if empty
+	FieldTag            string   // json if empty, see SetFieldTag
+	IntWidth            int      // see SetIntWidth
+	DuplicateNamePolicy DuplicateNamePolicy
+	IndentJSON          bool
+	ReflectGoNames      bool
+	ReflectGoTypes      bool
+	AllowBodyForGET     bool
+	FormatHeuristics    bool
+	HoistAnonymous      bool
+	NullablePointers    bool
+	StrictParameters    bool
+}
+
 // NewGenerator create a new Generator
 func NewGenerator() *Generator {
+	return NewGeneratorWithOptions(Options{})
+}
+
+// NewGeneratorWithOptions creates a new Generator with settings of opts
+func NewGeneratorWithOptions(opts Options) *Generator {
 	g := &Generator{}
+	g.initRegistries()
 
-	g.definitions = make(map[reflect.Type]SchemaObj)
-	g.definitionAdded = make(map[string]bool)
+	g.fieldTag = opts.FieldTag
+	if g.fieldTag == "" {
+		g.fieldTag = "json"
+	}
 
-	g.defQueue = make(map[reflect.Type]struct{})
-	g.paths = make(map[string]PathItem) // list all of paths object
-	g.typesMap = make(map[reflect.Type]interface{})
+	g.refPrefix = opts.RefPrefix
+	if g.refPrefix == "" {
+		g.refPrefix = refDefinitionPrefix
+	}
+
+	g.host = opts.Host
+	g.intWidth = opts.IntWidth
+	g.duplicateNamePolicy = opts.DuplicateNamePolicy
+	g.indentJSON = opts.IndentJSON
+	g.reflectGoNames = opts.ReflectGoNames
+	g.reflectGoTypes = opts.ReflectGoTypes
+	g.allowBodyForGET = opts.AllowBodyForGET
+	g.formatHeuristics = opts.FormatHeuristics
+	g.hoistAnonymous = opts.HoistAnonymous
+	g.nullablePointers = opts.NullablePointers
+	g.strictParameters = opts.StrictParameters
 
 	g.doc.Schemes = []string{"http", "https"}
+	if len(opts.Schemes) > 0 {
+		g.doc.Schemes = append([]string(nil), opts.Schemes...)
+	}
 	g.doc.Paths = make(map[string]PathItem)
 	g.doc.Definitions = make(map[string]SchemaObj)
+	g.doc.Parameters = make(map[string]ParamObj)
+	g.doc.Responses = make(map[string]ResponseObj)
 	g.doc.SecurityDefinitions = make(map[string]SecurityDef)
 	g.doc.Version = "2.0"
-	g.doc.BasePath = "/"
+	g.doc.BasePath = "/" + strings.Trim(opts.BasePath, "/")
 
 	// set default Access-Control-Allow-Headers of swagger.json
 	g.corsAllowHeaders = []string{"Content-Type", "api_key", "Authorization"}
@@ -73,19 +162,382 @@ func NewGenerator() *Generator {
 	return g
 }
 
+// initRegistries creates empty maps of definitions, paths and registered types,
+// it is shared by constructor and Clone, so a new registry has to be created only here
+func (g *Generator) initRegistries() {
+	g.definitions = make(map[reflect.Type]SchemaObj)
+	g.definitionAdded = make(map[string]bool)
+	g.defQueue = make(map[reflect.Type]struct{})
+	g.defPaths = make(map[reflect.Type]string)
+	g.paths = make(map[string]PathItem) // list all of paths object
+	g.typesMap = make(map[reflect.Type]interface{})
+	g.interfaceImpls = make(map[reflect.Type][]reflect.Type)
+	g.typeRefs = make(map[reflect.Type]string)
+	g.paramSets = make(map[string][]ParamObj)
+	g.rawDefinitions = make(map[string]json.RawMessage)
+	g.typeFormats = make(map[reflect.Type]typeFormat)
+	g.commonNames = make(map[string]SchemaObj)
+	g.goTypes = make(map[reflect.Type]string)
+	g.uuidTypes = make(map[reflect.Type]bool)
+	g.definitionOpts = make(map[reflect.Type]*definitionOptions)
+}
+
+// Clone returns a deep copy of Generator with its document, definitions, paths and options,
+// changes of the copy do not affect the original and vice versa
+func (g *Generator) Clone() *Generator {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	c := &Generator{
+		doc:                  g.doc.clone(),
+		host:                 g.host,
+		refPrefix:            g.refPrefix,
+		duplicateNamePolicy:  g.duplicateNamePolicy,
+		indentJSON:           g.indentJSON,
+		reflectGoNames:       g.reflectGoNames,
+		reflectGoTypes:       g.reflectGoTypes,
+		propertyNamer:        g.propertyNamer,
+		responseWrapper:      g.responseWrapper,
+		fieldTag:             g.fieldTag,
+		allowBodyForGET:      g.allowBodyForGET,
+		formatHeuristics:     g.formatHeuristics,
+		hoistAnonymous:       g.hoistAnonymous,
+		intWidth:             g.intWidth,
+		nullablePointers:     g.nullablePointers,
+		warnUnexportedTagged: g.warnUnexportedTagged,
+		strictParameters:     g.strictParameters,
+		inferRequired:        g.inferRequired,
+		emitPropertyOrder:    g.emitPropertyOrder,
+		inlineSingleUse:      g.inlineSingleUse,
+		autoContentType:      g.autoContentType,
+	}
+	c.initRegistries()
+
+	g.corsMu.RLock()
+	c.corsEnabled = g.corsEnabled
+	c.corsAllowHeaders = append([]string(nil), g.corsAllowHeaders...)
+	g.corsMu.RUnlock()
+
+	for name, added := range g.definitionAdded {
+		c.definitionAdded[name] = added
+	}
+	for t, typeDef := range g.definitions {
+		c.definitions[t] = typeDef
+	}
+	for t := range g.defQueue {
+		c.defQueue[t] = struct{}{}
+	}
+	for t, path := range g.defPaths {
+		c.defPaths[t] = path
+	}
+	for path, item := range g.paths {
+		c.paths[path] = item.clone()
+	}
+	for t := range g.uuidTypes {
+		c.uuidTypes[t] = true
+	}
+	for src, dst := range g.typesMap {
+		c.typesMap[src] = dst
+	}
+	for t, impls := range g.interfaceImpls {
+		c.interfaceImpls[t] = append([]reflect.Type(nil), impls...)
+	}
+	for t, ref := range g.typeRefs {
+		c.typeRefs[t] = ref
+	}
+	for name, params := range g.paramSets {
+		c.paramSets[name] = append([]ParamObj(nil), params...)
+	}
+	for name, raw := range g.rawDefinitions {
+		c.rawDefinitions[name] = raw
+	}
+	for name, schema := range g.commonNames {
+		c.commonNames[name] = schema
+	}
+	for t, tf := range g.typeFormats {
+		c.typeFormats[t] = tf
+	}
+	for t, opts := range g.definitionOpts {
+		optsCopy := *opts
+		c.definitionOpts[t] = &optsCopy
+	}
+	if g.statusDescriptions != nil {
+		c.statusDescriptions = make(map[int]string, len(g.statusDescriptions))
+		for status, description := range g.statusDescriptions {
+			c.statusDescriptions[status] = description
+		}
+	}
+	if g.paramDescriptions != nil {
+		c.paramDescriptions = make(map[string]string, len(g.paramDescriptions))
+		for name, description := range g.paramDescriptions {
+			c.paramDescriptions[name] = description
+		}
+	}
+	if g.docComments != nil {
+		c.docComments = make(map[string]string, len(g.docComments))
+		for key, doc := range g.docComments {
+			c.docComments[key] = doc
+		}
+	}
+
+	c.warnings = g.Warnings()
+
+	return c
+}
+
 // IndentJSON controls JSON indentation
 func (g *Generator) IndentJSON(enabled bool) *Generator {
 	g.mu.Lock()
 	g.indentJSON = enabled
 	g.mu.Unlock()
+	g.invalidateCache()
 	return g
 }
 
-// ReflectGoTypes controls JSON indentation
+// ReflectGoTypes controls adding of x-go-* vendor extensions with both names and types of Go fields,
+// see SetReflectGoNames and SetReflectGoTypes to control them separately
 func (g *Generator) ReflectGoTypes(enabled bool) *Generator {
 	g.mu.Lock()
+	g.reflectGoNames = enabled
 	g.reflectGoTypes = enabled
 	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetReflectGoNames controls adding of names of Go fields with x-go-name and x-go-property-names
+func (g *Generator) SetReflectGoNames(enabled bool) *Generator {
+	g.mu.Lock()
+	g.reflectGoNames = enabled
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetReflectGoTypes controls adding of Go types with x-go-type, x-go-package, x-go-property-types
+// and x-request-go-type, the types expose import paths of packages
+func (g *Generator) SetReflectGoTypes(enabled bool) *Generator {
+	g.mu.Lock()
+	g.reflectGoTypes = enabled
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// AllowBodyForGET controls documenting of request body for GET and HEAD operations,
+// by default such body is skipped with a warning
+func (g *Generator) AllowBodyForGET(enabled bool) *Generator {
+	g.mu.Lock()
+	g.allowBodyForGET = enabled
+	g.mu.Unlock()
+	return g
+}
+
+// SetRefPrefix sets prefix of definition references, "#/definitions/" by default
+func (g *Generator) SetRefPrefix(prefix string) *Generator {
+	g.mu.Lock()
+	g.refPrefix = prefix
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetDuplicateNamePolicy sets naming of definitions of different types having the same name,
+// DuplicateNameRename by default
+func (g *Generator) SetDuplicateNamePolicy(policy DuplicateNamePolicy) *Generator {
+	g.mu.Lock()
+	g.duplicateNamePolicy = policy
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetParamDescriptions sets descriptions of parameters by struct field name, they are used
+// for parameters without description tag, "-" tag disables description
+func (g *Generator) SetParamDescriptions(descriptions map[string]string) *Generator {
+	g.mu.Lock()
+	if g.paramDescriptions == nil {
+		g.paramDescriptions = make(map[string]string, len(descriptions))
+	}
+	for name, description := range descriptions {
+		g.paramDescriptions[name] = description
+	}
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetNullablePointers controls marking properties of pointer types with x-nullable,
+// references to definitions are wrapped with allOf to keep x-nullable next to them
+func (g *Generator) SetNullablePointers(enabled bool) *Generator {
+	g.mu.Lock()
+	g.nullablePointers = enabled
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetWarnUnexportedTagged controls recording a warning for unexported fields having the tag of property names,
+// such fields are skipped and the tag usually indicates a bug
+func (g *Generator) SetWarnUnexportedTagged(enabled bool) *Generator {
+	g.mu.Lock()
+	g.warnUnexportedTagged = enabled
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetIntWidth sets width in bits of int and uint types, with 64 they are described as int64 integers,
+// otherwise as int32 integers which is the default
+func (g *Generator) SetIntWidth(bits int) *Generator {
+	g.mu.Lock()
+	g.intWidth = bits
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetHoistAnonymous controls whether anonymous structs are added to definitions named as Inline1, Inline2, etc.
+// and referenced instead of being inlined, identical anonymous structs share one definition
+func (g *Generator) SetHoistAnonymous(enabled bool) *Generator {
+	g.mu.Lock()
+	g.hoistAnonymous = enabled
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetFormatHeuristics controls detection of formats of string properties and parameters without explicit format:
+// fields named like *Email get email format, fields named like *URL get uri format and
+// types registered with RegisterUUIDType get uuid format
+func (g *Generator) SetFormatHeuristics(enabled bool) *Generator {
+	g.mu.Lock()
+	g.formatHeuristics = enabled
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// RegisterUUIDType registers types described with uuid format when format heuristics are enabled
+func (g *Generator) RegisterUUIDType(types ...interface{}) *Generator {
+	g.mu.Lock()
+	for _, i := range types {
+		t := reflect.TypeOf(i)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		g.uuidTypes[t] = true
+	}
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetStrictNames makes parsing of a definition fail if its name is already used by another type
+// instead of renaming it, it is a shorthand for SetDuplicateNamePolicy(DuplicateNameError)
+func (g *Generator) SetStrictNames(enabled bool) *Generator {
+	if enabled {
+		return g.SetDuplicateNamePolicy(DuplicateNameError)
+	}
+	return g.SetDuplicateNamePolicy(DuplicateNameRename)
+}
+
+// SetStrictParameters makes SetPathItem fail if operation has several parameters with the same name and location
+// instead of keeping the last of them with a warning
+func (g *Generator) SetStrictParameters(enabled bool) *Generator {
+	g.mu.Lock()
+	g.strictParameters = enabled
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetInferRequired makes properties of definitions required unless their tag has omitempty option,
+// required tag overrides the inferred value, e.g. `json:"name,omitempty" required:"true"`
+func (g *Generator) SetInferRequired(enabled bool) *Generator {
+	g.mu.Lock()
+	g.inferRequired = enabled
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetEmitPropertyOrder controls adding x-order vendor extension with declaration order of struct fields
+// to properties of definitions, so that UI tools can show properties in that order instead of alphabetical one
+func (g *Generator) SetEmitPropertyOrder(enabled bool) *Generator {
+	g.mu.Lock()
+	g.emitPropertyOrder = enabled
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetInlineSingleUse controls inlining of definitions referenced only once in document into the place
+// of reference, recursive definitions and bases of polymorphic models are never inlined
+func (g *Generator) SetInlineSingleUse(enabled bool) *Generator {
+	g.mu.Lock()
+	g.inlineSingleUse = enabled
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetAutoContentType controls inferring of application/json MIME type that operation consumes if it has body
+// and produces if it has response with schema, explicitly set types, e.g. of form body, are kept
+func (g *Generator) SetAutoContentType(enabled bool) *Generator {
+	g.mu.Lock()
+	g.autoContentType = enabled
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetStatusDescriptions sets descriptions of responses added without explicit description by HTTP status,
+// they are merged over built-in defaults like "not found" for 404
+func (g *Generator) SetStatusDescriptions(descriptions map[int]string) *Generator {
+	g.mu.Lock()
+	if g.statusDescriptions == nil {
+		g.statusDescriptions = make(map[int]string, len(descriptions))
+	}
+	for status, description := range descriptions {
+		g.statusDescriptions[status] = description
+	}
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetPropertyNamer sets function that computes property names of definitions from struct fields,
+// empty name returned by namer falls back to the name from json tag or the one set with SetFieldTag
+func (g *Generator) SetPropertyNamer(namer func(field reflect.StructField) string) *Generator {
+	g.mu.Lock()
+	g.propertyNamer = namer
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetResponseWrapper sets function that wraps schema of every response parsed from a Go value into an envelope,
+// e.g. {"data": inner, "meta": {...}}, inner schema is usually a reference, nil wrapper disables wrapping
+func (g *Generator) SetResponseWrapper(wrapper func(inner SchemaObj) SchemaObj) *Generator {
+	g.mu.Lock()
+	g.responseWrapper = wrapper
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetFieldTag sets name of struct tag to read property names from instead of json, e.g. bson,
+// empty name restores json
+func (g *Generator) SetFieldTag(name string) *Generator {
+	if name == "" {
+		name = "json"
+	}
+
+	g.mu.Lock()
+	g.fieldTag = name
+	g.mu.Unlock()
+	g.invalidateCache()
 	return g
 }
 
@@ -118,6 +570,7 @@ func (g *Generator) SetHost(host string) *Generator {
 	g.mu.Lock()
 	g.host = host
 	g.mu.Unlock()
+	g.invalidateCache()
 	return g
 }
 
@@ -127,6 +580,16 @@ func (g *Generator) SetBasePath(basePath string) *Generator {
 	g.mu.Lock()
 	g.doc.BasePath = basePath
 	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetSchemes set transfer protocols of API, "http" and "https" by default, no schemes omits the field
+func (g *Generator) SetSchemes(schemes ...string) *Generator {
+	g.mu.Lock()
+	g.doc.Schemes = schemes
+	g.mu.Unlock()
+	g.invalidateCache()
 	return g
 }
 
@@ -141,10 +604,14 @@ func (g *Generator) SetContact(name, url, email string) *Generator {
 	g.mu.Lock()
 	g.doc.Info.Contact = ct
 	g.mu.Unlock()
+	g.invalidateCache()
 	return g
 }
 
-// SetInfo set information about API
+// defaultInfoVersion is used in document when version of API is not set
+const defaultInfoVersion = "1.0.0"
+
+// SetInfo set information about API, empty version defaults to "1.0.0" in generated document
 func (g *Generator) SetInfo(title, description, term, version string) *Generator {
 	info := InfoObj{
 		Title:          title,
@@ -156,6 +623,7 @@ func (g *Generator) SetInfo(title, description, term, version string) *Generator
 	g.mu.Lock()
 	g.doc.Info = info
 	g.mu.Unlock()
+	g.invalidateCache()
 	return g
 }
 
@@ -169,6 +637,7 @@ func (g *Generator) SetLicense(name, url string) *Generator {
 	g.mu.Lock()
 	g.doc.Info.License = ls
 	g.mu.Unlock()
+	g.invalidateCache()
 	return g
 }
 
@@ -177,6 +646,18 @@ func (g *Generator) AddExtendedField(name string, value interface{}) *Generator
 	g.mu.Lock()
 	g.doc.AddExtendedField(name, value)
 	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// AddTagGroup adds group of tags to x-tagGroups vendor extension of document that is used by ReDoc to group tags
+func (g *Generator) AddTagGroup(name string, tags ...string) *Generator {
+	g.mu.Lock()
+	groups, _ := g.doc.data["x-tagGroups"].([]TagGroup)
+	groups = append(groups[:len(groups):len(groups)], TagGroup{Name: name, Tags: tags})
+	g.doc.AddExtendedField("x-tagGroups", groups)
+	g.mu.Unlock()
+	g.invalidateCache()
 	return g
 }
 
@@ -185,17 +666,200 @@ func (g *Generator) AddSecurityDefinition(name string, def SecurityDef) *Generat
 	g.mu.Lock()
 	g.doc.SecurityDefinitions[name] = def
 	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// AddGlobalParameter adds parameter to document that can be referenced by name from operations
+// with PathItemInfo.Parameters
+func (g *Generator) AddGlobalParameter(name string, p ParamObj) *Generator {
+	g.mu.Lock()
+	g.doc.Parameters[name] = p
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// AddGlobalResponse adds response to document that can be referenced by name from operations
+// with PathItemInfo.Responses
+func (g *Generator) AddGlobalResponse(name string, r ResponseObj) *Generator {
+	g.mu.Lock()
+	g.doc.Responses[name] = r
+	g.mu.Unlock()
+	g.invalidateCache()
 	return g
 }
 
+// AddRawDefinition adds hand-written JSON schema object that is emitted verbatim as definition with name,
+// it can be referenced with MapTypeToRef, name must not be used by other definitions
+func (g *Generator) AddRawDefinition(name string, rawJSON json.RawMessage) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(rawJSON, &object); err != nil || object == nil {
+		return fmt.Errorf("raw definition %s is not a JSON object", name)
+	}
+
+	g.mu.Lock()
+	if g.definitionAdded[name] {
+		g.mu.Unlock()
+		return fmt.Errorf("duplicate definition name %s", name)
+	}
+	g.definitionAdded[name] = true
+	g.rawDefinitions[name] = append(json.RawMessage(nil), rawJSON...)
+	g.mu.Unlock()
+	g.invalidateCache()
+	return nil
+}
+
 // AddTypeMap add rule to use dst interface instead of src
 func (g *Generator) AddTypeMap(src interface{}, dst interface{}) *Generator {
 	g.mu.Lock()
 	g.typesMap[reflect.TypeOf(src)] = dst
 	g.mu.Unlock()
+	g.invalidateCache()
 	return g
 }
 
+// MapTypeFormat makes schemas of type t have given type and format, e.g. "string" and "uuid",
+// wherever the type is used instead of being described by its kind or definition
+func (g *Generator) MapTypeFormat(t reflect.Type, typ, format string) *Generator {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	g.mu.Lock()
+	g.typeFormats[t] = typeFormat{Type: typ, Format: format}
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// RegisterCommonName adds name that can be used in swgen_type tag, e.g. swgen_type:"money",
+// to describe field with schema, registered names take precedence over built-in ones
+func (g *Generator) RegisterCommonName(name string, schema SchemaObj) *Generator {
+	g.mu.Lock()
+	g.commonNames[name] = schema
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// MapTypeToRef makes schemas of type of i a reference to definition in external document,
+// e.g. "common.json#/definitions/Money", instead of a local definition
+func (g *Generator) MapTypeToRef(i interface{}, ref string) *Generator {
+	t := reflect.TypeOf(i)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	g.mu.Lock()
+	g.typeRefs[t] = ref
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// RegisterInterfaceImplementations registers concrete types implementing an interface, iface should be
+// a nil pointer to the interface, e.g. (*io.Reader)(nil). Fields of that interface type are described with
+// a reference to a definition listing all implementations in x-oneOf
+func (g *Generator) RegisterInterfaceImplementations(iface interface{}, impls ...interface{}) *Generator {
+	t := reflect.TypeOf(iface)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Interface {
+		panic("RegisterInterfaceImplementations expects a pointer to interface, got " + t.String())
+	}
+
+	g.mu.Lock()
+	for _, impl := range impls {
+		g.interfaceImpls[t] = append(g.interfaceImpls[t], reflect.TypeOf(impl))
+	}
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetDiscriminator sets property of i definition that acts as a type selector for polymorphic models,
+// the property is added to required properties too
+func (g *Generator) SetDiscriminator(i interface{}, propertyName string) *Generator {
+	g.mu.Lock()
+	g.setDefinitionOptions(i, func(opts *definitionOptions) {
+		opts.discriminator = propertyName
+	})
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetDefinitionTitle sets human-friendly title of i definition
+func (g *Generator) SetDefinitionTitle(i interface{}, title string) *Generator {
+	g.mu.Lock()
+	g.setDefinitionOptions(i, func(opts *definitionOptions) {
+		opts.title = title
+	})
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetDefinitionExample sets example of definition of i, the example is marshaled to JSON immediately
+// and it panics if the example can not be marshaled
+func (g *Generator) SetDefinitionExample(i interface{}, example interface{}) *Generator {
+	data, err := json.Marshal(example)
+	if err != nil {
+		panic("SetDefinitionExample could not marshal example: " + err.Error())
+	}
+
+	g.mu.Lock()
+	g.setDefinitionOptions(i, func(opts *definitionOptions) {
+		opts.example = data
+	})
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// SetRequiredGroup adds group of properties of i definition where at least one property is required,
+// Swagger 2.0 can not express such constraint so it is only documented with x-required-one-of extension
+func (g *Generator) SetRequiredGroup(i interface{}, group []string) *Generator {
+	group = append([]string(nil), group...)
+
+	g.mu.Lock()
+	g.setDefinitionOptions(i, func(opts *definitionOptions) {
+		groups := make([][]string, len(opts.requiredOneOf), len(opts.requiredOneOf)+1)
+		copy(groups, opts.requiredOneOf)
+		opts.requiredOneOf = append(groups, group)
+	})
+	g.mu.Unlock()
+	g.invalidateCache()
+	return g
+}
+
+// Warnings returns problems found while parsing definitions and parameters that did not stop generation
+func (g *Generator) Warnings() []string {
+	g.warnMu.Lock()
+	defer g.warnMu.Unlock()
+	return append([]string(nil), g.warnings...)
+}
+
+func (g *Generator) warnf(format string, args ...interface{}) {
+	g.warnMu.Lock()
+	g.warnings = append(g.warnings, fmt.Sprintf(format, args...))
+	g.warnMu.Unlock()
+}
+
+// warnOnce records warning unless the same warning is already recorded
+func (g *Generator) warnOnce(warning string) {
+	g.warnMu.Lock()
+	defer g.warnMu.Unlock()
+	for _, w := range g.warnings {
+		if w == warning {
+			return
+		}
+	}
+	g.warnings = append(g.warnings, warning)
+}
+
 func (g *Generator) getMappedType(t reflect.Type) (dst interface{}, found bool) {
 	dst, found = g.typesMap[t]
 	return
@@ -216,9 +880,28 @@ func (g *Generator) genDocument(host *string) ([]byte, error) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	if err := g.prepareDocument(host); err != nil {
+		return nil, err
+	}
+	return g.marshalJSON(g.doc)
+}
+
+// prepareDocument fills document with parsed definitions and paths, g.mu must be locked
+func (g *Generator) prepareDocument(host *string) error {
 	// ensure that all definition in queue is parsed before generating
-	g.parseDefInQueue()
-	g.doc.Definitions = g.definitions.GenDefinitions()
+	if err := g.parseDefInQueue(context.Background()); err != nil {
+		return err
+	}
+	g.doc.Definitions = g.genDefinitions()
+
+	// version and title of API are required by specification
+	if g.doc.Info.Version == "" {
+		g.doc.Info.Version = defaultInfoVersion
+	}
+	if g.doc.Info.Title == "" {
+		g.warnOnce("info.title is empty, it is required by specification, use SetInfo to set it")
+	}
+
 	if g.host != "" || host == nil {
 		g.doc.Host = g.host
 	} else {
@@ -243,17 +926,18 @@ func (g *Generator) genDocument(host *string) ([]byte, error) {
 		g.doc.Paths[path] = item
 	}
 
-	var (
-		data []byte
-		err  error
-	)
-	if g.indentJSON {
-		data, err = json.MarshalIndent(g.doc, "", "  ")
-	} else {
-		data, err = json.Marshal(g.doc)
+	if g.inlineSingleUse {
+		g.inlineSingleUseDefinitions()
 	}
+	return nil
+}
 
-	return data, err
+// marshalJSON encodes v respecting JSON indentation setting
+func (g *Generator) marshalJSON(v interface{}) ([]byte, error) {
+	if g.indentJSON {
+		return json.MarshalIndent(v, "", "  ")
+	}
+	return json.Marshal(v)
 }
 
 // GenDocument returns document specification in JSON string (in []byte)
@@ -262,11 +946,175 @@ func (g *Generator) GenDocument() ([]byte, error) {
 	return g.genDocument(nil)
 }
 
-// ServeHTTP implements http.Handler to server swagger.json document
+// WriteDocument writes document specification in JSON to w without buffering it,
+// output is the same as of GenDocument followed by a newline
+func (g *Generator) WriteDocument(w io.Writer) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// pass nil here to set host as g.host
+	if err := g.prepareDocument(nil); err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	if g.indentJSON {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(g.doc)
+}
+
+// GenDefinitions returns definitions of parsed types and raw definitions as a standalone JSON object (in []byte)
+func (g *Generator) GenDefinitions() ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// ensure that all definition in queue is parsed before generating
+	if err := g.parseDefInQueue(context.Background()); err != nil {
+		return nil, err
+	}
+	return g.marshalJSON(g.genDefinitions())
+}
+
+// Definitions returns copy of parsed and raw definitions by type name, queued definitions are parsed first,
+// error of their parsing is recorded as a warning, see Warnings
+func (g *Generator) Definitions() map[string]SchemaObj {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.parseDefInQueue(context.Background()); err != nil {
+		g.warnf("parsing of queued definitions failed, definitions are incomplete: %v", err)
+	}
+	return g.genDefinitions()
+}
+
+// genDefinitions returns parsed definitions merged with raw definitions by name, g.mu must be locked
+func (g *Generator) genDefinitions() map[string]SchemaObj {
+	definitions := g.definitions.GenDefinitions()
+	if definitions == nil {
+		definitions = make(map[string]SchemaObj, len(g.rawDefinitions))
+	}
+	for name, raw := range g.rawDefinitions {
+		definitions[name] = SchemaObj{raw: raw}
+	}
+	return definitions
+}
+
+// Paths returns copy of registered path items by path, operations are copied too
+func (g *Generator) Paths() map[string]PathItem {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	paths := make(map[string]PathItem, len(g.paths))
+	for path, item := range g.paths {
+		paths[path] = item.clone()
+	}
+	return paths
+}
+
+// PathDependencies returns sorted names of definitions referenced by parameters and responses of operations
+// of each path directly or through other definitions, all queued definitions are parsed first,
+// empty result is returned and warning is recorded if document can not be prepared
+func (g *Generator) PathDependencies() map[string][]string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.prepareDocument(nil); err != nil {
+		g.warnf("preparing of document failed, path dependencies are unknown: %v", err)
+		return map[string][]string{}
+	}
+
+	result := make(map[string][]string, len(g.doc.Paths))
+	for path, item := range g.doc.Paths {
+		visited := make(map[string]bool)
+		for _, method := range operationMethods {
+			op := item.operation(method)
+			if op == nil {
+				continue
+			}
+
+			for _, param := range op.Parameters {
+				if param.Ref != "" {
+					param = g.doc.Parameters[strings.TrimPrefix(param.Ref, refParameterPrefix)]
+				}
+				if param.Schema != nil {
+					g.collectDependencies(param.Schema, visited)
+				}
+			}
+			for _, response := range op.Responses {
+				if response.Ref != "" {
+					response = g.doc.Responses[strings.TrimPrefix(response.Ref, refResponsePrefix)]
+				}
+				if response.Schema != nil {
+					g.collectDependencies(response.Schema, visited)
+				}
+			}
+		}
+
+		names := make([]string, 0, len(visited))
+		for name := range visited {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		result[path] = names
+	}
+	return result
+}
+
+// collectDependencies adds names of definitions referenced from schema to visited following references,
+// definitions that are already visited are not walked again, so cyclic references are fine
+func (g *Generator) collectDependencies(schema *SchemaObj, visited map[string]bool) {
+	walkSchema(schema, func(s *SchemaObj) {
+		if !strings.HasPrefix(s.Ref, g.refPrefix) {
+			return
+		}
+
+		name := strings.TrimPrefix(s.Ref, g.refPrefix)
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		if def, ok := g.doc.Definitions[name]; ok {
+			g.collectDependencies(&def, visited)
+		}
+	})
+}
+
+// invalidateCache drops documents cached by ServeHTTP, it must not be called with g.mu locked
+func (g *Generator) invalidateCache() {
+	g.cacheMu.Lock()
+	g.docCache = nil
+	g.cacheMu.Unlock()
+}
+
+// cachedDocument returns document for host generating it once until next change of generator
+func (g *Generator) cachedDocument(host string) ([]byte, error) {
+	g.cacheMu.Lock()
+	defer g.cacheMu.Unlock()
+
+	if data, ok := g.docCache[host]; ok {
+		return data, nil
+	}
+
+	data, err := g.genDocument(&host)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.docCache == nil {
+		g.docCache = make(map[string][]byte)
+	}
+	g.docCache[host] = data
+	return data, nil
+}
+
+// ServeHTTP implements http.Handler to server swagger.json document,
+// document is generated once and cached until generator is changed
 func (g *Generator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	data, err := g.genDocument(&r.URL.Host)
+	data, err := g.cachedDocument(r.URL.Host)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
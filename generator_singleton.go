@@ -1,6 +1,10 @@
 package swgen
 
-import "net/http"
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
 
 // singleton package generator
 var gen = NewGenerator()
@@ -20,6 +24,11 @@ func SetBasePath(basePath string) *Generator {
 	return gen.SetBasePath(basePath)
 }
 
+// SetSchemes set transfer protocols of API
+func SetSchemes(schemes ...string) *Generator {
+	return gen.SetSchemes(schemes...)
+}
+
 // SetContact set contact information for API
 func SetContact(name, url, email string) *Generator {
 	return gen.SetContact(name, url, email)
@@ -40,6 +49,26 @@ func AddExtendedField(name string, value interface{}) *Generator {
 	return gen.AddExtendedField(name, value)
 }
 
+// AddTagGroup adds group of tags to x-tagGroups vendor extension of document
+func AddTagGroup(name string, tags ...string) *Generator {
+	return gen.AddTagGroup(name, tags...)
+}
+
+// AddGlobalParameter adds parameter to document that can be referenced by name from operations
+func AddGlobalParameter(name string, p ParamObj) *Generator {
+	return gen.AddGlobalParameter(name, p)
+}
+
+// AddGlobalResponse adds response to document that can be referenced by name from operations
+func AddGlobalResponse(name string, r ResponseObj) *Generator {
+	return gen.AddGlobalResponse(name, r)
+}
+
+// AddRawDefinition adds hand-written JSON schema object that is emitted verbatim as definition with name
+func AddRawDefinition(name string, rawJSON json.RawMessage) error {
+	return gen.AddRawDefinition(name, rawJSON)
+}
+
 // AddTypeMap add rule to use dst interface instead of src
 func AddTypeMap(src interface{}, dst interface{}) *Generator {
 	return gen.AddTypeMap(src, dst)
@@ -50,6 +79,16 @@ func GenDocument() ([]byte, error) {
 	return gen.GenDocument()
 }
 
+// WriteDocument writes document specification in JSON to w
+func WriteDocument(w io.Writer) error {
+	return gen.WriteDocument(w)
+}
+
+// GenDefinitions returns definitions of parsed types as a standalone JSON object (in []byte)
+func GenDefinitions() ([]byte, error) {
+	return gen.GenDefinitions()
+}
+
 // ServeHTTP implements http.HandleFunc to server swagger.json document
 func ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	gen.ServeHTTP(w, r)
@@ -1,6 +1,7 @@
 package swgen
 
 import (
+	"bytes"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
@@ -8,12 +9,16 @@ import (
 	"os"
 	"path"
 	"reflect"
+	"regexp"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/kr/pretty"
 	"github.com/lazada/swgen/sample"
+	v1models "github.com/lazada/swgen/sample/v1/models"
+	v2models "github.com/lazada/swgen/sample/v2/models"
 )
 
 type TestSampleStruct struct {
@@ -287,6 +292,7 @@ func TestREST(t *testing.T) {
 		SetContact("Dylan Noblitt", "http://example.com", "dylan.noblitt@example.com").
 		AddExtendedField("x-service-type", ServiceTypeRest).
 		ReflectGoTypes(true).
+		AllowBodyForGET(true).
 		IndentJSON(true)
 
 	gen.AddTypeMap(simpleTestReplacement{}, "")
@@ -551,3 +557,1084 @@ func TestCORSSupport(t *testing.T) {
 	assertTrue(w.Header().Get("Access-Control-Allow-Methods") == "GET, POST, DELETE, PUT, PATCH, OPTIONS", t)
 	assertTrue(w.Header().Get("Access-Control-Allow-Headers") == "Content-Type, api_key, Authorization, X-ABC-Test", t)
 }
+
+func TestServeHTTPCache(t *testing.T) {
+	g := NewGenerator()
+
+	serve := func() Document {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", "http://localhost:1234/docs/swagger.json", nil)
+		if err != nil {
+			t.Fatalf("error when create request: %v", err)
+		}
+
+		g.ServeHTTP(w, r)
+
+		if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+			t.Fatalf("unexpected content type %q", ct)
+		}
+
+		doc := Document{}
+		if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("could not get response: %v", err)
+		}
+		if doc.Version != "2.0" {
+			t.Fatalf("unexpected swagger version %q", doc.Version)
+		}
+		return doc
+	}
+
+	info := PathItemInfo{Path: "/v1/people", Method: "GET", Title: "ListPeople"}
+	if err := g.SetPathItem(info, nil, nil, []Person{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	if doc := serve(); len(doc.Paths) != 1 {
+		t.Fatalf("unexpected paths %v", doc.Paths)
+	}
+	if len(g.docCache) != 1 {
+		t.Fatalf("document should be cached")
+	}
+
+	info = PathItemInfo{Path: "/v1/pets", Method: "GET", Title: "ListPets"}
+	if err := g.SetPathItem(info, nil, nil, []testPet{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	if doc := serve(); len(doc.Paths) != 2 {
+		t.Fatalf("cached document should be invalidated, got paths %v", doc.Paths)
+	}
+
+	g.ResetPaths()
+	if doc := serve(); len(doc.Paths) != 0 {
+		t.Fatalf("cached document should be invalidated, got paths %v", doc.Paths)
+	}
+}
+
+func TestGlobalParameters(t *testing.T) {
+	g := NewGenerator()
+	g.AddGlobalParameter("page", ParamObj{Name: "page", In: "query", Type: "integer", Format: "int32"})
+
+	info := PathItemInfo{
+		Path:       "/v1/people",
+		Method:     "GET",
+		Title:      "ListPeople",
+		Parameters: []string{"page"},
+	}
+	if err := g.SetPathItem(info, nil, nil, []Person{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	var doc struct {
+		Parameters map[string]map[string]interface{} `json:"parameters"`
+		Paths      map[string]map[string]struct {
+			Parameters []map[string]interface{} `json:"parameters"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	if doc.Parameters["page"]["in"] != "query" {
+		t.Fatalf("global parameter is missing: %v", doc.Parameters)
+	}
+
+	params := doc.Paths["/v1/people"]["get"].Parameters
+	expected := []map[string]interface{}{{"$ref": "#/parameters/page"}}
+	if !reflect.DeepEqual(params, expected) {
+		t.Fatalf("unexpected parameters %v", params)
+	}
+
+	info.Path = "/v2/people"
+	info.Parameters = []string{"limit"}
+	if err := g.SetPathItem(info, nil, nil, []Person{}); err == nil {
+		t.Fatal("error expected for undefined global parameter")
+	}
+}
+
+func TestGlobalResponses(t *testing.T) {
+	g := NewGenerator()
+	g.AddGlobalResponse("NotFound", ResponseObj{Description: "entity not found"})
+
+	info := PathItemInfo{
+		Path:      "/v1/people/{id}",
+		Method:    "GET",
+		Title:     "GetPerson",
+		Responses: map[int]string{http.StatusNotFound: "NotFound"},
+	}
+	if err := g.SetPathItem(info, nil, nil, Person{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	var doc struct {
+		Responses map[string]map[string]interface{} `json:"responses"`
+		Paths     map[string]map[string]struct {
+			Responses map[string]map[string]interface{} `json:"responses"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	if doc.Responses["NotFound"]["description"] != "entity not found" {
+		t.Fatalf("global response is missing: %v", doc.Responses)
+	}
+
+	responses := doc.Paths["/v1/people/{id}"]["get"].Responses
+	if !reflect.DeepEqual(responses["404"], map[string]interface{}{"$ref": "#/responses/NotFound"}) {
+		t.Fatalf("unexpected 404 response %v", responses["404"])
+	}
+	if _, ok := responses["200"]; !ok {
+		t.Fatalf("success response is missing %v", responses)
+	}
+
+	info.Path = "/v2/people/{id}"
+	info.Responses = map[int]string{http.StatusConflict: "Conflict"}
+	if err := g.SetPathItem(info, nil, nil, Person{}); err == nil {
+		t.Fatal("error expected for undefined global response")
+	}
+}
+
+func TestSetPropertyNamer(t *testing.T) {
+	type Account struct {
+		Login    string
+		FullName string `json:"full_name"`
+		Password string `json:"-"`
+	}
+
+	g := NewGenerator()
+	g.SetPropertyNamer(func(field reflect.StructField) string {
+		if field.Tag.Get("json") != "" {
+			return ""
+		}
+		return strings.ToLower(field.Name)
+	})
+
+	if _, err := g.ParseDefinition(Account{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Account{}))
+	names := make([]string, 0, len(typeDef.Properties))
+	for name := range typeDef.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !reflect.DeepEqual(names, []string{"full_name", "login"}) {
+		t.Fatalf("unexpected property names %v", names)
+	}
+}
+
+type testShape interface {
+	Area() float64
+}
+
+type testCircle struct {
+	Radius float64 `json:"radius"`
+}
+
+func (c testCircle) Area() float64 { return 3.14 * c.Radius * c.Radius }
+
+type testSquare struct {
+	Side float64 `json:"side"`
+}
+
+func (s testSquare) Area() float64 { return s.Side * s.Side }
+
+type testDrawing struct {
+	Main   testShape   `json:"main"`
+	Shapes []testShape `json:"shapes"`
+}
+
+func TestRegisterInterfaceImplementations(t *testing.T) {
+	g := NewGenerator()
+	g.RegisterInterfaceImplementations((*testShape)(nil), testCircle{}, testSquare{})
+
+	if _, err := g.ParseDefinition(testDrawing{Main: testSquare{}}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	drawing, _ := g.getDefinition(reflect.TypeOf(testDrawing{}))
+	if ref := drawing.Properties["main"].Ref; ref != "#/definitions/testShape" {
+		t.Fatalf("unexpected reference of main property: %q", ref)
+	}
+	if ref := drawing.Properties["shapes"].Items.Ref; ref != "#/definitions/testShape" {
+		t.Fatalf("unexpected reference of shapes items: %q", ref)
+	}
+
+	shape, found := g.getDefinition(reflect.TypeOf((*testShape)(nil)).Elem())
+	if !found {
+		t.Fatal("No definition for testShape")
+	}
+
+	refs := make([]string, 0, len(shape.OneOf))
+	for _, impl := range shape.OneOf {
+		refs = append(refs, impl.Ref)
+	}
+	if !reflect.DeepEqual(refs, []string{"#/definitions/testCircle", "#/definitions/testSquare"}) {
+		t.Fatalf("unexpected implementations %v", refs)
+	}
+
+	for _, impl := range []interface{}{testCircle{}, testSquare{}} {
+		if _, found := g.getDefinition(reflect.TypeOf(impl)); !found {
+			t.Fatalf("No definition for %T", impl)
+		}
+	}
+}
+
+type testPet struct {
+	PetType string `json:"pet_type"`
+	Name    string `json:"name"`
+}
+
+func TestSetDiscriminator(t *testing.T) {
+	g := NewGenerator()
+	g.SetDiscriminator(testPet{}, "pet_type")
+
+	if _, err := g.ParseDefinition(&testPet{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var doc struct {
+		Definitions map[string]struct {
+			Discriminator string   `json:"discriminator"`
+			Required      []string `json:"required"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	pet := doc.Definitions["testPet"]
+	if pet.Discriminator != "pet_type" {
+		t.Fatalf("unexpected discriminator %q", pet.Discriminator)
+	}
+	if !reflect.DeepEqual(pet.Required, []string{"pet_type"}) {
+		t.Fatalf("unexpected required properties %v", pet.Required)
+	}
+}
+
+func TestDiscriminatorTag(t *testing.T) {
+	type Vehicle struct {
+		Kind   string `json:"kind" discriminator:"true"`
+		Wheels int    `json:"wheels"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Vehicle{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	vehicle, _ := g.getDefinition(reflect.TypeOf(Vehicle{}))
+	if vehicle.Discriminator != "kind" || !reflect.DeepEqual(vehicle.Required, []string{"kind"}) {
+		t.Fatalf("unexpected discriminator %q with required %v", vehicle.Discriminator, vehicle.Required)
+	}
+}
+
+func TestDefinitionExample(t *testing.T) {
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(PersonName{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	example := PersonName{First: "John", Last: "Doe"}
+	g.SetDefinitionExample(&PersonName{}, example)
+	example.First = "Jane"
+
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var doc struct {
+		Definitions map[string]struct {
+			Example json.RawMessage `json:"example"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if example := string(doc.Definitions["PersonName"].Example); example != `{"first_name":"John","middle_name":"","last_name":"Doe","Nickname":""}` {
+		t.Fatalf("unexpected example %s", example)
+	}
+}
+
+func TestRequiredGroup(t *testing.T) {
+	type ContactRequest struct {
+		Email string `json:"email"`
+		Phone string `json:"phone"`
+		Fax   string `json:"fax"`
+	}
+
+	g := NewGenerator()
+	g.SetRequiredGroup(ContactRequest{}, []string{"email", "phone", "fax"})
+	if _, err := g.ParseDefinition(ContactRequest{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var doc struct {
+		Definitions map[string]struct {
+			RequiredOneOf [][]string `json:"x-required-one-of"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if groups := doc.Definitions["ContactRequest"].RequiredOneOf; !reflect.DeepEqual(groups, [][]string{{"email", "phone", "fax"}}) {
+		t.Fatalf("unexpected required groups %v", groups)
+	}
+}
+
+type rawMoney struct{}
+
+func TestAddRawDefinition(t *testing.T) {
+	type Order struct {
+		Total rawMoney `json:"total"`
+	}
+
+	raw := json.RawMessage(`{"allOf":[{"$ref":"#/definitions/Amount"},{"not":{"required":["fraction"]}}]}`)
+
+	g := NewGenerator()
+	if err := g.AddRawDefinition("Money", raw); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := g.AddRawDefinition("Money", raw); err == nil {
+		t.Fatal("duplicate name error expected")
+	}
+	if err := g.AddRawDefinition("Amount", json.RawMessage(`["number"]`)); err == nil {
+		t.Fatal("not an object error expected")
+	}
+
+	g.MapTypeToRef(rawMoney{}, "#/definitions/Money")
+	if _, err := g.ParseDefinition(Order{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var doc struct {
+		Definitions map[string]json.RawMessage `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if money := string(doc.Definitions["Money"]); money != string(raw) {
+		t.Fatalf("unexpected raw definition %s", money)
+	}
+	if order := string(doc.Definitions["Order"]); !strings.Contains(order, `"total":{"$ref":"#/definitions/Money"}`) {
+		t.Fatalf("unexpected definition %s", order)
+	}
+
+	data, err = g.GenDefinitions()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	var definitions map[string]json.RawMessage
+	if err := json.Unmarshal(data, &definitions); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if money := string(definitions["Money"]); money != string(raw) {
+		t.Fatalf("unexpected raw definition in GenDefinitions %s", money)
+	}
+
+	money, found := g.Definitions()["Money"]
+	if !found {
+		t.Fatal("raw definition expected in Definitions")
+	}
+	if data, err := json.Marshal(money); err != nil || string(data) != string(raw) {
+		t.Fatalf("unexpected raw definition in Definitions %s, %v", data, err)
+	}
+}
+
+func TestDefinitionTitle(t *testing.T) {
+	type UserAccount struct {
+		_    struct{} `swgen_title:"User Account"`
+		Name string   `json:"name"`
+	}
+	type Team struct {
+		Owner   UserAccount `json:"owner"`
+		Members []testPet   `json:"members"`
+	}
+
+	g := NewGenerator()
+	g.SetDefinitionTitle(testPet{}, "Pet")
+
+	info := PathItemInfo{Path: "/v1/team", Method: "GET", Title: "GetTeam"}
+	if err := g.SetPathItem(info, nil, nil, Team{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var doc struct {
+		Definitions map[string]map[string]interface{} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if title := doc.Definitions["UserAccount"]["title"]; title != "User Account" {
+		t.Fatalf("unexpected UserAccount title %v", title)
+	}
+	if title := doc.Definitions["testPet"]["title"]; title != "Pet" {
+		t.Fatalf("unexpected testPet title %v", title)
+	}
+	if _, found := doc.Definitions["Team"]["title"]; found {
+		t.Fatal("unexpected Team title")
+	}
+
+	owner := doc.Definitions["Team"]["properties"].(map[string]interface{})["owner"].(map[string]interface{})
+	if _, found := owner["title"]; found {
+		t.Fatalf("title should not be set on reference %v", owner)
+	}
+}
+
+func TestSetRefPrefix(t *testing.T) {
+	g := NewGenerator()
+	g.SetRefPrefix("#/components/schemas/")
+
+	info := PathItemInfo{Path: "/v1/people", Method: "POST", Title: "CreatePeople"}
+	if err := g.SetPathItem(info, nil, []Person{}, NullTypes{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	refs := regexp.MustCompile(`"\$ref":"([^"]*)"`).FindAllStringSubmatch(string(data), -1)
+	if len(refs) == 0 {
+		t.Fatal("references expected in document")
+	}
+	for _, ref := range refs {
+		if !strings.HasPrefix(ref[1], "#/components/schemas/") {
+			t.Fatalf("unexpected reference %s", ref[1])
+		}
+	}
+}
+
+func TestGenDefinitions(t *testing.T) {
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(testSimpleStruct{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if _, err := g.ParseDefinition(testSimpleSlices{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	data, err := g.GenDefinitions()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	definitions := make(map[string]SchemaObj)
+	if err := json.Unmarshal(data, &definitions); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !reflect.DeepEqual(names, []string{"testSimpleSlices", "testSimpleStruct"}) {
+		t.Fatalf("unexpected definitions %v", names)
+	}
+	if len(definitions["testSimpleStruct"].Properties) != 9 {
+		t.Fatalf("unexpected properties %v", definitions["testSimpleStruct"].Properties)
+	}
+}
+
+func TestWriteDocument(t *testing.T) {
+	for _, indent := range []bool{false, true} {
+		g := NewGenerator().IndentJSON(indent)
+		info := PathItemInfo{Path: "/v1/people", Method: "POST", Title: "CreatePeople"}
+		if err := g.SetPathItem(info, nil, []Person{}, NullTypes{}); err != nil {
+			t.Fatalf("error %v", err)
+		}
+
+		expected, err := g.GenDocument()
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := g.WriteDocument(&buf); err != nil {
+			t.Fatalf("error %v", err)
+		}
+
+		if !bytes.Equal(buf.Bytes(), append(expected, '\n')) {
+			t.Fatalf("unexpected document %s, expected %s", buf.String(), string(expected))
+		}
+	}
+}
+
+func TestSetDuplicateNamePolicy(t *testing.T) {
+	parse := func(policy DuplicateNamePolicy) (*Generator, error) {
+		g := NewGenerator().SetDuplicateNamePolicy(policy)
+		if _, err := g.ParseDefinition(TestSampleStruct{}); err != nil {
+			t.Fatalf("%v", err)
+		}
+		_, err := g.ParseDefinition(sample.TestSampleStruct{})
+		return g, err
+	}
+
+	g, err := parse(DuplicateNameRename)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if def, _ := g.getDefinition(reflect.TypeOf(sample.TestSampleStruct{})); def.TypeName != "TestSampleStructType2" {
+		t.Fatalf("unexpected name %q", def.TypeName)
+	}
+
+	if _, err := parse(DuplicateNameError); err == nil || !strings.Contains(err.Error(), "TestSampleStruct") {
+		t.Fatalf("expected duplicate name error, got %v", err)
+	}
+
+	g, err = parse(DuplicateNamePackage)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	def, _ := g.getDefinition(reflect.TypeOf(sample.TestSampleStruct{}))
+	const name = "github.com_lazada_swgen_sample.TestSampleStruct"
+	if def.TypeName != name || def.Ref != "#/definitions/"+name {
+		t.Fatalf("unexpected name %q with ref %q", def.TypeName, def.Ref)
+	}
+}
+
+func TestDuplicateNamePackageSameName(t *testing.T) {
+	type User struct {
+		ID int `json:"id"`
+	}
+
+	g := NewGenerator().SetDuplicateNamePolicy(DuplicateNamePackage)
+	for _, i := range []interface{}{User{}, v1models.User{}, v2models.User{}} {
+		if _, err := g.ParseDefinition(i); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+
+	definitions := g.Definitions()
+	for _, name := range []string{"User", "github.com_lazada_swgen_sample_v1_models.User", "github.com_lazada_swgen_sample_v2_models.User"} {
+		if _, found := definitions[name]; !found {
+			t.Fatalf("definition %s expected in %v", name, definitions)
+		}
+	}
+	if errs := g.Validate(); len(errs) != 0 {
+		t.Fatalf("unexpected validation errors %v", errs)
+	}
+}
+
+func TestDuplicateNameErrorInQueue(t *testing.T) {
+	type Holder struct {
+		Sample sample.TestSampleStruct `json:"sample"`
+	}
+
+	g := NewGenerator().SetDuplicateNamePolicy(DuplicateNameError)
+	if _, err := g.ParseDefinition(TestSampleStruct{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if _, err := g.ParseDefinition(Holder{}); err == nil {
+		t.Fatal("expected duplicate name error for queued definition")
+	}
+}
+
+func TestDefinitionsQueueError(t *testing.T) {
+	g := NewGenerator().SetDuplicateNamePolicy(DuplicateNameError)
+	if _, err := g.ParseDefinition(TestSampleStruct{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	g.addToDefQueue(reflect.TypeOf(sample.TestSampleStruct{}))
+
+	if _, found := g.Definitions()["TestSampleStruct"]; !found {
+		t.Fatal("parsed definition expected")
+	}
+	warnings := g.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "TestSampleStruct") {
+		t.Fatalf("expected duplicate name warning, got %v", warnings)
+	}
+}
+
+func TestSetSchemes(t *testing.T) {
+	g := NewGenerator().SetSchemes("https")
+
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if !strings.Contains(string(data), `"schemes":["https"]`) {
+		t.Fatalf("unexpected schemes in %s", data)
+	}
+
+	data, err = g.SetSchemes().GenDocument()
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if strings.Contains(string(data), `"schemes"`) {
+		t.Fatalf("schemes should be omitted in %s", data)
+	}
+}
+
+func TestDefinitionsAndPaths(t *testing.T) {
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/people", Method: "POST", Title: "CreatePerson"}
+	if err := g.SetPathItem(info, nil, Person{}, testPet{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	names := make([]string, 0)
+	for name := range g.Definitions() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"Person", "PersonName", "testPet"}) {
+		t.Fatalf("unexpected definitions %v", names)
+	}
+
+	paths := g.Paths()
+	if len(paths) != 1 || paths["/v1/people"].Post == nil {
+		t.Fatalf("unexpected paths %v", paths)
+	}
+
+	paths["/v1/people"].Post.Summary = "changed"
+	if g.Paths()["/v1/people"].Post.Summary == "changed" {
+		t.Fatal("operations should be copied")
+	}
+
+	delete(paths, "/v1/people")
+	if len(g.Paths()) != 1 {
+		t.Fatal("paths should be copied")
+	}
+}
+
+func firstFoo() interface{} {
+	type Foo struct {
+		A int `json:"a"`
+	}
+	return Foo{}
+}
+
+func secondFoo() interface{} {
+	type Foo struct {
+		B string `json:"b"`
+	}
+	return Foo{}
+}
+
+func TestSetStrictNames(t *testing.T) {
+	g := NewGenerator().SetStrictNames(true)
+	if _, err := g.ParseDefinition(firstFoo()); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if _, err := g.ParseDefinition(firstFoo()); err != nil {
+		t.Fatalf("same type should be parsed again, got %v", err)
+	}
+	if _, err := g.ParseDefinition(secondFoo()); err == nil {
+		t.Fatal("duplicate name error expected")
+	}
+
+	g.SetStrictNames(false)
+	schema, err := g.ParseDefinition(secondFoo())
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if schema.TypeName != "FooType2" {
+		t.Fatalf("unexpected name %q", schema.TypeName)
+	}
+}
+
+func TestOmitEmptySections(t *testing.T) {
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/ping", Method: "GET", Title: "Ping"}
+	if err := g.SetPathItem(info, nil, nil, nil); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	for _, section := range []string{`"definitions"`, `"securityDefinitions"`, `"parameters":{}`, `"responses":{}`} {
+		if strings.Contains(string(data), section) {
+			t.Fatalf("empty %s should be omitted in %s", section, data)
+		}
+	}
+
+	data, err = NewGenerator().GenDocument()
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if !strings.Contains(string(data), `"paths":{}`) {
+		t.Fatalf("paths are required by specification and should be kept in %s", data)
+	}
+}
+
+func TestClone(t *testing.T) {
+	base := NewGenerator().SetInfo("Base API", "", "", "1.0.0")
+	base.AddSecurityDefinition("BasicAuth", SecurityDef{Type: SecurityBasicAuth})
+
+	info := PathItemInfo{Path: "/v1/people", Method: "GET", Title: "ListPeople", Security: []string{"BasicAuth"}}
+	if err := base.SetPathItem(info, nil, nil, []Person{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	expected, err := base.GenDocument()
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	tenant := base.Clone()
+	tenant.SetInfo("Tenant API", "", "", "1.0.0")
+	tenant.AddSecurityDefinition("ApiKey", SecurityDef{Type: SecurityAPIKey, In: "header", Name: "X-Api-Key"})
+	tenant.AddExtendedField("x-tenant", "acme")
+
+	info = PathItemInfo{Path: "/v1/pets", Method: "GET", Title: "ListPets"}
+	if err := tenant.SetPathItem(info, nil, nil, []testPet{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	b, err := tenant.SetPathItemWithResponses(PathItemInfo{Path: "/v1/people", Method: "GET"}, nil, nil)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	b.Response(http.StatusNotFound, nil, "")
+
+	data, err := base.GenDocument()
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if string(data) != string(expected) {
+		t.Fatalf("original document is changed:\n%s\nexpected:\n%s", data, expected)
+	}
+
+	data, err = tenant.GenDocument()
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	for _, s := range []string{`"Tenant API"`, `"/v1/pets"`, `"testPet"`, `"ApiKey"`, `"x-tenant"`, `"404"`, `"Person"`} {
+		if !strings.Contains(string(data), s) {
+			t.Fatalf("%s not found in cloned document %s", s, data)
+		}
+	}
+	type Paging struct {
+		Limit int `schema:"limit"`
+	}
+	type Filter struct {
+		Query string `schema:"q"`
+	}
+
+	if err := base.RegisterParameterSet("Paging", Paging{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	tenant = base.Clone()
+	if err := tenant.RegisterParameterSet("Filter", Filter{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	info = PathItemInfo{Path: "/v1/orders", Method: "GET", Title: "ListOrders", ParameterSet: "Paging"}
+	if err := tenant.SetPathItem(info, nil, nil, nil); err != nil {
+		t.Fatalf("parameter set of original should be cloned: %v", err)
+	}
+	if _, found := base.paramSets["Filter"]; found {
+		t.Fatal("parameter set of clone should not be added to original")
+	}
+
+	// Go types are reflected by clones of generators with Go types enabled before and after cloning
+	for _, clone := range []*Generator{base.Clone().ReflectGoTypes(true), base.ReflectGoTypes(true).Clone()} {
+		schema, err := clone.ParseDefinition(testPet{})
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if pet := clone.Definitions()[strings.TrimPrefix(schema.Ref, "#/definitions/")]; pet.GoType == "" {
+			t.Fatalf("go type should be reflected by clone, %#v received", pet)
+		}
+	}
+
+	type Event struct {
+		ID       mappedID      `json:"id"`
+		Duration time.Duration `json:"duration"`
+	}
+
+	base.MapTypeFormat(reflect.TypeOf(mappedID{}), "string", "uuid")
+	tenant = base.Clone()
+	tenant.MapTypeFormat(reflect.TypeOf(time.Duration(0)), "string", "duration")
+	schema, err := tenant.ParseDefinition(Event{})
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	event := tenant.Definitions()[strings.TrimPrefix(schema.Ref, "#/definitions/")]
+	if id := event.Properties["id"]; id.Type != "string" || id.Format != "uuid" {
+		t.Fatalf("type format of original should be cloned, %#v received", id)
+	}
+	if duration := event.Properties["duration"]; duration.Type != "string" || duration.Format != "duration" {
+		t.Fatalf("type format of clone should be used, %#v received", duration)
+	}
+	if _, found := base.typeFormats[reflect.TypeOf(time.Duration(0))]; found {
+		t.Fatal("type format of clone should not be added to original")
+	}
+
+	if err := base.AddRawDefinition("Legacy", json.RawMessage(`{"type":"object"}`)); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	tenant = base.Clone()
+	if err := tenant.AddRawDefinition("LegacyTenant", json.RawMessage(`{"type":"string"}`)); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	data, err = tenant.GenDocument()
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	for _, s := range []string{`"Legacy"`, `"LegacyTenant"`} {
+		if !strings.Contains(string(data), s) {
+			t.Fatalf("%s not found in cloned document %s", s, data)
+		}
+	}
+	if _, found := base.rawDefinitions["LegacyTenant"]; found {
+		t.Fatal("raw definition of clone should not be added to original")
+	}
+
+	type Invoice struct {
+		Amount string `json:"amount" swgen_type:"money"`
+		Rate   string `json:"rate" swgen_type:"rate"`
+	}
+
+	base.RegisterCommonName("money", SchemaObj{Type: "string", Format: "money"})
+	tenant = base.Clone()
+	tenant.RegisterCommonName("rate", SchemaObj{Type: "number", Format: "double"})
+	schema, err = tenant.ParseDefinition(Invoice{})
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	invoice := tenant.Definitions()[strings.TrimPrefix(schema.Ref, "#/definitions/")]
+	if invoice.Properties["amount"].Format != "money" || invoice.Properties["rate"].Format != "double" {
+		t.Fatalf("common names of original and clone should be used, %#v received", invoice.Properties)
+	}
+	if _, found := base.commonNames["rate"]; found {
+		t.Fatal("common name of clone should not be added to original")
+	}
+}
+
+func TestInfoDefaults(t *testing.T) {
+	g := NewGenerator()
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if doc.Info.Version != "1.0.0" {
+		t.Fatalf("empty version should default to 1.0.0, %q received", doc.Info.Version)
+	}
+
+	if _, err := g.GenDocument(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	warnings := g.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "info.title") {
+		t.Fatalf("single warning about empty title expected, %v received", warnings)
+	}
+
+	g = NewGenerator().SetInfo("API", "", "", "2.1")
+	data, err = g.GenDocument()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if doc.Info.Version != "2.1" || len(g.Warnings()) != 0 {
+		t.Fatalf("unexpected version %q or warnings %v", doc.Info.Version, g.Warnings())
+	}
+}
+
+func TestAddSecurityDefinitionAPIKey(t *testing.T) {
+	g := NewGenerator().AddSecurityDefinition("APIKey", SecurityDef{Type: SecurityAPIKey, In: APIKeyInHeader, Name: "X-API-Key"})
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var doc struct {
+		SecurityDefinitions map[string]json.RawMessage `json:"securityDefinitions"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if def := string(doc.SecurityDefinitions["APIKey"]); def != `{"type":"apiKey","in":"header","name":"X-API-Key"}` {
+		t.Fatalf("unexpected security definition %s", def)
+	}
+}
+
+func TestAddSecurityDefinitionOAuth2(t *testing.T) {
+	g := NewGenerator().AddSecurityDefinition("OAuth2", SecurityDef{
+		Type:             SecurityOAuth2,
+		Flow:             Oauth2AccessCode,
+		AuthorizationURL: "https://example.com/oauth/authorize",
+		TokenURL:         "https://example.com/oauth/token",
+		Scopes: map[string]string{
+			"read":  "Grants read access",
+			"write": "Grants write access",
+		},
+	})
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var doc struct {
+		SecurityDefinitions map[string]json.RawMessage `json:"securityDefinitions"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("%v", err)
+	}
+	expected := `{"type":"oauth2","flow":"accessCode","authorizationUrl":"https://example.com/oauth/authorize",` +
+		`"tokenUrl":"https://example.com/oauth/token","scopes":{"read":"Grants read access","write":"Grants write access"}}`
+	if def := string(doc.SecurityDefinitions["OAuth2"]); def != expected {
+		t.Fatalf("unexpected security definition %s", def)
+	}
+}
+
+func TestNewGeneratorWithOptions(t *testing.T) {
+	g := NewGeneratorWithOptions(Options{
+		Host:                "api.example.com",
+		BasePath:            "api/v1/",
+		Schemes:             []string{"https"},
+		RefPrefix:           "#/components/schemas/",
+		FieldTag:            "bson",
+		IntWidth:            64,
+		DuplicateNamePolicy: DuplicateNamePackage,
+		IndentJSON:          true,
+		ReflectGoNames:      true,
+		ReflectGoTypes:      true,
+		AllowBodyForGET:     true,
+		FormatHeuristics:    true,
+		HoistAnonymous:      true,
+		NullablePointers:    true,
+		StrictParameters:    true,
+	})
+
+	if g.host != "api.example.com" || g.doc.BasePath != "/api/v1" || !reflect.DeepEqual(g.doc.Schemes, []string{"https"}) {
+		t.Fatalf("unexpected host %q, base path %q and schemes %v", g.host, g.doc.BasePath, g.doc.Schemes)
+	}
+	if g.refPrefix != "#/components/schemas/" || g.fieldTag != "bson" || g.intWidth != 64 || g.duplicateNamePolicy != DuplicateNamePackage {
+		t.Fatalf("unexpected ref prefix %q, field tag %q, int width %d and duplicate name policy %v",
+			g.refPrefix, g.fieldTag, g.intWidth, g.duplicateNamePolicy)
+	}
+	if !g.indentJSON || !g.reflectGoNames || !g.reflectGoTypes || !g.allowBodyForGET || !g.formatHeuristics ||
+		!g.hoistAnonymous || !g.nullablePointers || !g.strictParameters {
+		t.Fatalf("all toggles should be enabled %+v", g)
+	}
+
+	defaults := NewGenerator()
+	if defaults.refPrefix != refDefinitionPrefix || defaults.fieldTag != "json" || defaults.doc.BasePath != "/" ||
+		!reflect.DeepEqual(defaults.doc.Schemes, []string{"http", "https"}) {
+		t.Fatalf("unexpected defaults %+v", defaults)
+	}
+}
+
+type depOrder struct {
+	Customer depCustomer `json:"customer"`
+}
+
+type depCustomer struct {
+	Address   depAddress `json:"address"`
+	LastOrder *depOrder  `json:"last_order"`
+}
+
+type depAddress struct {
+	City string `json:"city"`
+}
+
+func TestPathDependencies(t *testing.T) {
+	type OrderRequest struct {
+		ID int `path:"id"`
+	}
+
+	g := NewGenerator()
+	g.AddGlobalResponse("NotFound", ResponseObj{Description: "not found", Schema: &SchemaObj{Ref: "#/definitions/depAddress"}})
+	info := PathItemInfo{Path: "/v1/orders/{id}", Method: "GET", Title: "GetOrder", Responses: map[int]string{http.StatusNotFound: "NotFound"}}
+	if err := g.SetPathItem(info, OrderRequest{}, nil, depOrder{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := g.SetPathItem(PathItemInfo{Path: "/v1/ping", Method: "GET", Title: "Ping"}, nil, nil, nil); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	dependencies := g.PathDependencies()
+	expected := map[string][]string{
+		"/v1/orders/{id}": {"depAddress", "depCustomer", "depOrder"},
+		"/v1/ping":        {},
+	}
+	if !reflect.DeepEqual(dependencies, expected) {
+		t.Fatalf("unexpected dependencies %v", dependencies)
+	}
+
+	g.SetDuplicateNamePolicy(DuplicateNameError)
+	if _, err := g.ParseDefinition(TestSampleStruct{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	g.addToDefQueue(reflect.TypeOf(sample.TestSampleStruct{}))
+	if dependencies = g.PathDependencies(); len(dependencies) != 0 {
+		t.Fatalf("no dependencies expected for failed document, got %v", dependencies)
+	}
+	if warnings := g.Warnings(); !strings.Contains(warnings[len(warnings)-1], "duplicate definition name TestSampleStruct") {
+		t.Fatalf("expected duplicate name warning, got %v", warnings)
+	}
+}
+
+func TestAddTagGroup(t *testing.T) {
+	g := NewGenerator()
+	g.AddExtendedField("x-logo", map[string]string{"url": "https://example.com/logo.png"})
+	g.AddTagGroup("Users", "people", "accounts").
+		AddTagGroup("Billing", "invoices")
+
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if _, ok := doc["paths"]; !ok {
+		t.Fatalf("extensions should be merged with document fields %s", data)
+	}
+	if logo := string(doc["x-logo"]); logo != `{"url":"https://example.com/logo.png"}` {
+		t.Fatalf("unexpected root extension %s", logo)
+	}
+	if groups := string(doc["x-tagGroups"]); groups != `[{"name":"Users","tags":["people","accounts"]},{"name":"Billing","tags":["invoices"]}]` {
+		t.Fatalf("unexpected tag groups %s", groups)
+	}
+}
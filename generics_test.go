@@ -0,0 +1,77 @@
+//go:build go1.18
+// +build go1.18
+
+package swgen
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/lazada/swgen/sample"
+)
+
+type genericPage[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+func TestGenericDefinitionName(t *testing.T) {
+	type Response struct {
+		People genericPage[sample.TestSampleStruct] `json:"people"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Response{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	const name = "genericPage_github.com_lazada_swgen_sample.TestSampleStruct_"
+	definitions := g.Definitions()
+	if _, found := definitions[name]; !found {
+		t.Fatalf("definition %s expected in %v", name, definitions)
+	}
+	if ref := definitions["Response"].Properties["people"].Ref; ref != "#/definitions/"+name {
+		t.Fatalf("unexpected reference %q", ref)
+	}
+	if errs := g.Validate(); len(errs) != 0 {
+		t.Fatalf("unexpected validation errors %v", errs)
+	}
+}
+
+type Nullable[T any] struct {
+	Value T
+	Valid bool
+}
+
+func (n Nullable[T]) SwgenUnwrap() interface{} {
+	return n.Value
+}
+
+func TestNullableWrapper(t *testing.T) {
+	type Profile struct {
+		Nickname Nullable[string]                  `json:"nickname"`
+		Sample   Nullable[sample.TestSampleStruct] `json:"sample"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Profile{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	definitions := g.Definitions()
+	data, err := json.Marshal(definitions["Profile"].Properties)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	expected := `{"nickname":{"type":"string","x-nullable":true},` +
+		`"sample":{"allOf":[{"$ref":"#/definitions/TestSampleStruct"}],"x-nullable":true}}`
+	if string(data) != expected {
+		t.Fatalf("unexpected properties %s", data)
+	}
+	for name := range definitions {
+		if strings.HasPrefix(name, "Nullable") {
+			t.Fatalf("unexpected wrapper definition %s", name)
+		}
+	}
+}
@@ -12,10 +12,10 @@ func ReflectTypeHash(t reflect.Type) uint32 {
 }
 
 // ReflectTypeReliableName returns real name of given reflect.Type, if it is non-empty, or auto-generates "anon_*"]
-// name for anonymous structs
+// name for anonymous structs, characters that are not safe in definition references are replaced with underscores
 func ReflectTypeReliableName(t reflect.Type) string {
 	if t.Name() != "" {
-		return t.Name()
+		return definitionName(t.Name())
 	}
 	return fmt.Sprintf("anon_%08x", ReflectTypeHash(t))
 }
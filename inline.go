@@ -0,0 +1,175 @@
+package swgen
+
+import "strings"
+
+// inlineSingleUseDefinitions replaces references to definitions that are referenced only once in document
+// with the definitions themselves and removes them from definitions, recursive definitions,
+// bases of polymorphic models, raw definitions and definitions referenced by global parameters,
+// global responses or raw definitions are kept
+func (g *Generator) inlineSingleUseDefinitions() {
+	uses := make(map[string]int)
+	countUses := func(schema *SchemaObj) {
+		walkSchema(schema, func(s *SchemaObj) {
+			if strings.HasPrefix(s.Ref, g.refPrefix) {
+				uses[strings.TrimPrefix(s.Ref, g.refPrefix)]++
+			}
+		})
+	}
+
+	for _, def := range g.doc.Definitions {
+		countUses(&def)
+	}
+	for _, item := range g.doc.Paths {
+		for _, method := range operationMethods {
+			if op := item.operation(method); op != nil {
+				for _, param := range op.Parameters {
+					if param.Schema != nil {
+						countUses(param.Schema)
+					}
+				}
+				for _, response := range op.Responses {
+					if response.Schema != nil {
+						countUses(response.Schema)
+					}
+				}
+			}
+		}
+	}
+
+	// global parameters and responses are not rebuilt on every generation and raw definitions
+	// are emitted verbatim, so they are not changed
+	kept := make(map[string]bool)
+	keep := func(schema *SchemaObj) {
+		walkSchema(schema, func(s *SchemaObj) {
+			if strings.HasPrefix(s.Ref, g.refPrefix) {
+				kept[strings.TrimPrefix(s.Ref, g.refPrefix)] = true
+			}
+		})
+	}
+	for _, param := range g.doc.Parameters {
+		if param.Schema != nil {
+			keep(param.Schema)
+		}
+	}
+	for _, response := range g.doc.Responses {
+		if response.Schema != nil {
+			keep(response.Schema)
+		}
+	}
+	for _, def := range g.doc.Definitions {
+		if def.raw != nil {
+			keep(&def)
+		}
+	}
+
+	inlined := make(map[string]SchemaObj)
+	for name, def := range g.doc.Definitions {
+		if uses[name] == 1 && !kept[name] && def.raw == nil && def.Discriminator == "" && !g.isRecursiveDefinition(name) {
+			inlined[name] = def
+		}
+	}
+	if len(inlined) == 0 {
+		return
+	}
+
+	definitions := make(map[string]SchemaObj, len(g.doc.Definitions)-len(inlined))
+	for name, def := range g.doc.Definitions {
+		if _, ok := inlined[name]; !ok {
+			definitions[name] = g.inlineSchema(def, inlined)
+		}
+	}
+	g.doc.Definitions = definitions
+
+	// operations are shared with registered paths, so they are replaced with inlined copies
+	for path, item := range g.doc.Paths {
+		item = item.clone()
+		for _, method := range operationMethods {
+			op := item.operation(method)
+			if op == nil {
+				continue
+			}
+			for i, param := range op.Parameters {
+				if param.Schema != nil {
+					schema := g.inlineSchema(*param.Schema, inlined)
+					op.Parameters[i].Schema = &schema
+				}
+			}
+			for status, response := range op.Responses {
+				if response.Schema != nil {
+					schema := g.inlineSchema(*response.Schema, inlined)
+					response.Schema = &schema
+					op.Responses[status] = response
+				}
+			}
+		}
+		g.doc.Paths[path] = item
+	}
+}
+
+// isRecursiveDefinition checks if definition with name references itself directly or through other definitions
+func (g *Generator) isRecursiveDefinition(name string) bool {
+	visited := make(map[string]bool)
+	var references func(def SchemaObj) bool
+	references = func(def SchemaObj) (found bool) {
+		walkSchema(&def, func(s *SchemaObj) {
+			if found || !strings.HasPrefix(s.Ref, g.refPrefix) {
+				return
+			}
+			refName := strings.TrimPrefix(s.Ref, g.refPrefix)
+			if refName == name {
+				found = true
+				return
+			}
+			if !visited[refName] {
+				visited[refName] = true
+				found = references(g.doc.Definitions[refName])
+			}
+		})
+		return found
+	}
+	return references(g.doc.Definitions[name])
+}
+
+// inlineSchema returns copy of schema with references to inlined definitions replaced by the definitions,
+// vendor extensions of reference are added to inlined definition
+func (g *Generator) inlineSchema(schema SchemaObj, inlined map[string]SchemaObj) SchemaObj {
+	if def, ok := inlined[strings.TrimPrefix(schema.Ref, g.refPrefix)]; strings.HasPrefix(schema.Ref, g.refPrefix) && ok {
+		def = g.inlineSchema(def, inlined)
+		def.additionalData = def.additionalData.clone()
+		for name, value := range schema.data {
+			def.AddExtendedField(name, value)
+		}
+		return def
+	}
+
+	if schema.Items != nil {
+		items := g.inlineSchema(*schema.Items, inlined)
+		schema.Items = &items
+	}
+	if schema.AdditionalProperties != nil {
+		additionalProperties := g.inlineSchema(*schema.AdditionalProperties, inlined)
+		schema.AdditionalProperties = &additionalProperties
+	}
+	if schema.Properties != nil {
+		properties := make(map[string]SchemaObj, len(schema.Properties))
+		for name, property := range schema.Properties {
+			properties[name] = g.inlineSchema(property, inlined)
+		}
+		schema.Properties = properties
+	}
+	if schema.OneOf != nil {
+		oneOf := make([]SchemaObj, len(schema.OneOf))
+		for i, s := range schema.OneOf {
+			oneOf[i] = g.inlineSchema(s, inlined)
+		}
+		schema.OneOf = oneOf
+	}
+	if schema.AllOf != nil {
+		allOf := make([]SchemaObj, len(schema.AllOf))
+		for i, s := range schema.AllOf {
+			allOf[i] = g.inlineSchema(s, inlined)
+		}
+		schema.AllOf = allOf
+	}
+	return schema
+}
@@ -0,0 +1,118 @@
+package swgen
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type inlineAddress struct {
+	City string `json:"city"`
+}
+
+type inlineUser struct {
+	Name    string        `json:"name"`
+	Address inlineAddress `json:"address"`
+}
+
+type inlineNode struct {
+	Children []inlineNode `json:"children"`
+}
+
+type inlineReport struct {
+	Root inlineNode `json:"root"`
+}
+
+func TestInlineSingleUse(t *testing.T) {
+	g := NewGenerator().SetInlineSingleUse(true).SetEmitPropertyOrder(true)
+	for _, info := range []PathItemInfo{
+		{Path: "/v1/users/{id}", Method: "GET", Title: "GetUser"},
+		{Path: "/v1/users/{id}", Method: "PUT", Title: "UpdateUser"},
+	} {
+		if err := g.SetPathItem(info, nil, nil, inlineUser{}); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+	if err := g.SetPathItem(PathItemInfo{Path: "/v1/report", Method: "GET", Title: "GetReport"}, nil, nil, inlineReport{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var doc struct {
+		Definitions map[string]json.RawMessage `json:"definitions"`
+		Paths       map[string]map[string]struct {
+			Responses map[string]struct {
+				Schema json.RawMessage `json:"schema"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if len(doc.Definitions) != 2 || doc.Definitions["inlineUser"] == nil || doc.Definitions["inlineNode"] == nil {
+		t.Fatalf("only definitions used twice and recursive ones should be kept %s", data)
+	}
+	if user := string(doc.Definitions["inlineUser"]); user != `{"type":"object","properties":{"address":{"type":"object",`+
+		`"properties":{"city":{"type":"string","x-order":0}},"x-order":1},"name":{"type":"string","x-order":0}}}` {
+		t.Fatalf("unexpected definition with inlined property %s", user)
+	}
+	if report := string(doc.Paths["/v1/report"]["get"].Responses["200"].Schema); report != `{"type":"object",`+
+		`"properties":{"root":{"$ref":"#/definitions/inlineNode","x-order":0}}}` {
+		t.Fatalf("unexpected inlined response %s", report)
+	}
+	if ref := g.paths["/v1/report"].Get.Responses["200"].Schema.Ref; ref != "#/definitions/inlineReport" {
+		t.Fatalf("registered operation should not be changed, got %q", ref)
+	}
+	if errs := g.Validate(); len(errs) != 0 {
+		t.Fatalf("unexpected validation errors %v", errs)
+	}
+}
+
+func TestInlineSingleUseRawDefinitions(t *testing.T) {
+	type Order struct {
+		Total rawMoney `json:"total"`
+	}
+
+	g := NewGenerator().SetInlineSingleUse(true)
+	if err := g.AddRawDefinition("Money", json.RawMessage(`{"type":"object"}`)); err != nil {
+		t.Fatalf("%v", err)
+	}
+	raw := json.RawMessage(`{"type":"object","properties":{"address":{"$ref":"#/definitions/inlineAddress"}}}`)
+	if err := g.AddRawDefinition("Legacy", raw); err != nil {
+		t.Fatalf("%v", err)
+	}
+	g.MapTypeToRef(rawMoney{}, "#/definitions/Money")
+	if _, err := g.ParseDefinition(inlineAddress{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := g.SetPathItem(PathItemInfo{Path: "/v1/orders", Method: "GET", Title: "GetOrder"}, nil, nil, Order{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var doc struct {
+		Definitions map[string]json.RawMessage `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// definition referenced only from raw definition and raw definition used once are kept
+	if doc.Definitions["inlineAddress"] == nil || doc.Definitions["Money"] == nil || string(doc.Definitions["Legacy"]) != string(raw) {
+		t.Fatalf("unexpected definitions %s", data)
+	}
+	if doc.Definitions["Order"] != nil {
+		t.Fatalf("definition used once should be inlined %s", data)
+	}
+	if errs := g.Validate(); len(errs) != 0 {
+		t.Fatalf("unexpected validation errors %v", errs)
+	}
+}
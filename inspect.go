@@ -0,0 +1,117 @@
+package swgen
+
+import "reflect"
+
+// UnsupportedField describes field that can not be described with schema
+type UnsupportedField struct {
+	Path string // path of the field starting with type name, e.g. "User.Address.OnChange"
+	Type string // Go type of the field
+}
+
+// Inspect walks fields of types like definitions are parsed and reports fields of unsupported kinds,
+// e.g. channels, functions or interfaces without registered implementations, generator state is not changed
+func (g *Generator) Inspect(types ...interface{}) []UnsupportedField {
+	var result []UnsupportedField
+	visited := make(map[reflect.Type]bool)
+	for _, i := range types {
+		if i == nil {
+			continue
+		}
+
+		t := reflect.TypeOf(i)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		path := t.Name()
+		if path == "" {
+			path = t.String()
+		}
+
+		if !isSupportedKind(t) {
+			result = append(result, UnsupportedField{Path: path, Type: t.String()})
+			continue
+		}
+		result = g.inspectType(t, path, visited, result)
+	}
+	return result
+}
+
+// Inspect walks fields of types like definitions are parsed and reports fields of unsupported kinds
+func Inspect(types ...interface{}) []UnsupportedField {
+	return gen.Inspect(types...)
+}
+
+// inspectType appends unsupported fields of t and of types of its fields to result, visited types are skipped
+func (g *Generator) inspectType(t reflect.Type, path string, visited map[reflect.Type]bool, result []UnsupportedField) []UnsupportedField {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+
+	if mappedTo, ok := g.getMappedType(t); ok {
+		return g.inspectType(reflect.TypeOf(mappedTo), path, visited, result)
+	}
+	if _, ok := g.typeFormats[t]; ok {
+		return result
+	}
+	if _, ok := g.typeRefs[t]; ok {
+		return result
+	}
+	if nullable, ok := reflect.Zero(t).Interface().(INullable); ok {
+		if value := nullable.SwgenUnwrap(); value != nil {
+			return g.inspectType(reflect.TypeOf(value), path, visited, result)
+		}
+	}
+	if _, ok := reflect.Zero(t).Interface().(IDefinition); ok {
+		return result
+	}
+
+	if t.Kind() != reflect.Struct || t == typeOfTime || t.ConvertibleTo(typeOfTime) || visited[t] {
+		return result
+	}
+	visited[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() != reflect.Interface {
+			result = g.inspectType(field.Type, path, visited, result)
+			continue
+		}
+
+		// fields skipped or described with common name when definitions are parsed
+		tag := field.Tag.Get(g.fieldTag)
+		if tag == "-" || field.Tag.Get("swgen_type") != "" {
+			continue
+		}
+		if tag == "" && !field.Anonymous && g.propertyNamer == nil {
+			continue
+		}
+
+		fieldPath := path + "." + field.Name
+		if !isSupportedKind(field.Type) {
+			result = append(result, UnsupportedField{Path: fieldPath, Type: field.Type.String()})
+			continue
+		}
+
+		elem := field.Type
+		for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array || elem.Kind() == reflect.Map {
+			elem = elem.Elem()
+		}
+		if elem.Kind() == reflect.Interface {
+			impls, registered := g.interfaceImpls[elem]
+			if !registered && elem.NumMethod() > 0 {
+				result = append(result, UnsupportedField{Path: fieldPath, Type: field.Type.String()})
+			}
+			for _, impl := range impls {
+				result = g.inspectType(impl, fieldPath, visited, result)
+			}
+			continue
+		}
+
+		result = g.inspectType(field.Type, fieldPath, visited, result)
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package swgen
+
+import (
+	"reflect"
+	"testing"
+)
+
+type inspectHook interface {
+	Run() error
+}
+
+type inspectAddress struct {
+	City     string       `json:"city"`
+	OnChange func(string) `json:"on_change"`
+}
+
+type inspectUser struct {
+	Name     string           `json:"name"`
+	Address  inspectAddress   `json:"address"`
+	Updates  chan string      `json:"updates"`
+	Hooks    []inspectHook    `json:"hooks"`
+	Friends  []*inspectUser   `json:"friends"`
+	Meta     interface{}      `json:"meta"`
+	internal func()           // unexported fields are not described
+	Ignored  chan struct{}    `json:"-"`
+	Numbers  map[string][]int `json:"numbers"`
+}
+
+func TestInspect(t *testing.T) {
+	g := NewGenerator()
+	unsupported := g.Inspect(inspectUser{})
+
+	expected := []UnsupportedField{
+		{Path: "inspectUser.Address.OnChange", Type: "func(string)"},
+		{Path: "inspectUser.Updates", Type: "chan string"},
+		{Path: "inspectUser.Hooks", Type: "[]swgen.inspectHook"},
+	}
+	if !reflect.DeepEqual(unsupported, expected) {
+		t.Fatalf("unexpected fields %v, expected %v", unsupported, expected)
+	}
+
+	if len(g.definitions) != 0 || len(g.Warnings()) != 0 {
+		t.Fatal("generator state should not be changed")
+	}
+}
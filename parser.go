@@ -1,25 +1,37 @@
 package swgen
 
 import (
+	"bytes"
+	"context"
 	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"go/build"
+	"math/big"
+	"net/http"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 const (
 	refDefinitionPrefix = "#/definitions/"
+	refParameterPrefix  = "#/parameters/"
+	refResponsePrefix   = "#/responses/"
 )
 
 var (
 	typeOfJSONRawMsg      = reflect.TypeOf((*json.RawMessage)(nil)).Elem()
 	typeOfTime            = reflect.TypeOf((*time.Time)(nil)).Elem()
+	typeOfBigInt          = reflect.TypeOf((*big.Int)(nil)).Elem()
+	typeOfBigFloat        = reflect.TypeOf((*big.Float)(nil)).Elem()
 	typeOfTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	typeOfTextMarshaler   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
 )
 
 // IParameter allows to return custom parameters
@@ -32,32 +44,149 @@ type IDefinition interface {
 	SwgenDefinition() (typeName string, typeDef SchemaObj, err error)
 }
 
-func (g *Generator) addDefinition(t reflect.Type, typeDef *SchemaObj) {
+// INullable is implemented by wrappers of optional values, e.g. Nullable[T] struct with Value T and Valid bool,
+// SwgenUnwrap returns value of wrapped type, wrapper is described as nullable schema of that type
+type INullable interface {
+	SwgenUnwrap() interface{}
+}
+
+// IRequired allows struct to list names of its required properties instead of tagging fields
+type IRequired interface {
+	SwgenRequired() []string
+}
+
+func (g *Generator) addDefinition(t reflect.Type, typeDef *SchemaObj) error {
 	if typeDef.TypeName == "" {
-		return // there should be no anonymous definitions in Swagger JSON
+		return nil // there should be no anonymous definitions in Swagger JSON
 	}
 	if _, ok := g.definitions[t]; ok { // skip existing definition
-		return
+		return nil
+	}
+
+	if name := definitionName(typeDef.TypeName); name != typeDef.TypeName {
+		typeDef.TypeName = name
+		if typeDef.Ref != "" {
+			typeDef.Ref = g.refPrefix + name
+		}
 	}
 
 	if _, ok := g.definitionAdded[typeDef.TypeName]; ok { // process duplicate TypeName
 		var typeName string
-		typeIndex := 2
-		for {
-			typeName = fmt.Sprintf("%sType%d", typeDef.TypeName, typeIndex)
-			if _, ok := g.definitionAdded[typeName]; !ok {
-				break
+		switch g.duplicateNamePolicy {
+		case DuplicateNameError:
+			return fmt.Errorf("duplicate definition name %s of type %s", typeDef.TypeName, goType(t))
+		case DuplicateNamePackage:
+			typeName = packageQualifiedName(t)
+			if _, ok := g.definitionAdded[typeName]; ok {
+				return fmt.Errorf("duplicate definition name %s of type %s", typeName, goType(t))
+			}
+		default:
+			typeIndex := 2
+			for {
+				typeName = fmt.Sprintf("%sType%d", typeDef.TypeName, typeIndex)
+				if _, ok := g.definitionAdded[typeName]; !ok {
+					break
+				}
+				typeIndex++
 			}
-			typeIndex++
 		}
 
 		typeDef.TypeName = typeName
 		if typeDef.Ref != "" {
-			typeDef.Ref = refDefinitionPrefix + typeDef.TypeName
+			typeDef.Ref = g.refPrefix + typeDef.TypeName
 		}
 	}
+	g.applyDefinitionOptions(t, typeDef)
 	g.definitionAdded[typeDef.TypeName] = true
 	g.definitions[t] = *typeDef
+	return nil
+}
+
+// DuplicateNamePolicy defines naming of definitions of different types having the same name
+type DuplicateNamePolicy int
+
+const (
+	// DuplicateNameRename adds index to name of duplicate definition, e.g. ConfigType2
+	DuplicateNameRename DuplicateNamePolicy = iota
+	// DuplicateNameError fails parsing of duplicate definition
+	DuplicateNameError
+	// DuplicateNamePackage qualifies name of duplicate definition with import path of package,
+	// e.g. github.com_acme_api_v1_models.Config
+	DuplicateNamePackage
+)
+
+var regexNonNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// packageQualifiedName returns name of named type prefixed with import path of its package,
+// e.g. github.com_acme_api_v1_models.User, so that types of packages with the same name differ
+func packageQualifiedName(t reflect.Type) string {
+	return definitionName(importPath(t) + "." + t.Name())
+}
+
+// definitionName replaces characters that are not safe in JSON Pointer of definition reference,
+// e.g. slashes in names of generic types or in names returned by IDefinition, with underscores
+func definitionName(name string) string {
+	return regexNonNameChars.ReplaceAllString(name, "_")
+}
+
+// definitionOptions holds settings of a definition registered with Generator setters
+type definitionOptions struct {
+	discriminator string
+	title         string
+	example       json.RawMessage
+	requiredOneOf [][]string
+}
+
+// setDefinitionOptions updates options of i definition with f, applying them to the definition if it is already added
+func (g *Generator) setDefinitionOptions(i interface{}, f func(opts *definitionOptions)) {
+	t := reflect.TypeOf(i)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	opts, ok := g.definitionOpts[t]
+	if !ok {
+		opts = &definitionOptions{}
+		g.definitionOpts[t] = opts
+	}
+	f(opts)
+
+	if typeDef, ok := g.definitions[t]; ok {
+		g.applyDefinitionOptions(t, &typeDef)
+		g.definitions[t] = typeDef
+	}
+}
+
+func (g *Generator) applyDefinitionOptions(t reflect.Type, typeDef *SchemaObj) {
+	opts, ok := g.definitionOpts[t]
+	if !ok {
+		return
+	}
+
+	if opts.discriminator != "" {
+		typeDef.setDiscriminator(opts.discriminator)
+	}
+
+	if opts.title != "" {
+		typeDef.Title = opts.title
+	}
+
+	if opts.example != nil {
+		typeDef.Example = opts.example
+	}
+
+	if opts.requiredOneOf != nil {
+		typeDef.RequiredOneOf = opts.requiredOneOf
+	}
+}
+
+// newSchemaObj is NewSchemaObj that builds reference with the generator's prefix
+func (g *Generator) newSchemaObj(jsonType, typeName string) *SchemaObj {
+	so := NewSchemaObj(jsonType, typeName)
+	if so.Ref != "" {
+		so.Ref = g.refPrefix + typeName
+	}
+	return so
 }
 
 func (g *Generator) defExists(t reflect.Type) (b bool) {
@@ -93,9 +222,12 @@ func (g *Generator) deleteDefinition(t reflect.Type) {
 
 // ResetDefinitions will remove all exists definitions and init again
 func (g *Generator) ResetDefinitions() {
+	g.invalidateCache()
 	g.definitions = make(defMap)
 	g.definitionAdded = make(map[string]bool)
+	g.rawDefinitions = make(map[string]json.RawMessage)
 	g.defQueue = make(map[reflect.Type]struct{})
+	g.defPaths = make(map[reflect.Type]string)
 }
 
 // ResetDefinitions will remove all exists definitions and init again
@@ -106,6 +238,27 @@ func ResetDefinitions() {
 // ParseDefinition create a DefObj from input object, it should be a non-nil pointer to anything
 // it reuse schema/json tag for property name.
 func (g *Generator) ParseDefinition(i interface{}) (schema SchemaObj, err error) {
+	g.invalidateCache()
+	return g.parseDefinition(context.Background(), i)
+}
+
+// ParseDefinitionCtx is ParseDefinition that stops parsing of queued definitions and returns ctx.Err()
+// once ctx is done, definitions that are already added stay complete and the rest stay queued
+func (g *Generator) ParseDefinitionCtx(ctx context.Context, i interface{}) (schema SchemaObj, err error) {
+	if err = ctx.Err(); err != nil {
+		return schema, err
+	}
+
+	g.invalidateCache()
+	schema, err = g.parseDefinition(ctx, i)
+	if err == nil {
+		err = ctx.Err()
+	}
+	return schema, err
+}
+
+// parseDefinition parses definition of i and queued definitions, parsing of queued definitions stops once ctx is done
+func (g *Generator) parseDefinition(ctx context.Context, i interface{}) (schema SchemaObj, err error) {
 	var (
 		typeName string
 		typeDef  SchemaObj
@@ -129,36 +282,64 @@ func (g *Generator) ParseDefinition(i interface{}) (schema SchemaObj, err error)
 		}
 		typeDef.TypeName = typeName
 		if def, ok := g.getDefinition(t); ok {
-			return SchemaObj{Ref: refDefinitionPrefix + def.TypeName, TypeName: def.TypeName}, nil
+			return SchemaObj{Ref: g.refPrefix + def.TypeName, TypeName: def.TypeName}, nil
 		}
-		defer g.parseDefInQueue()
+		defer g.parseDefInQueueKeepError(ctx, &err)
 		if g.reflectGoTypes {
-			typeDef.GoType = goType(t)
+			typeDef.GoType = g.goType(t)
+		}
+		if err = g.addDefinition(t, &typeDef); err != nil {
+			return typeDef, err
 		}
-		g.addDefinition(t, &typeDef)
 
-		return SchemaObj{Ref: refDefinitionPrefix + typeDef.TypeName, TypeName: typeDef.TypeName}, nil
+		return SchemaObj{Ref: g.refPrefix + typeDef.TypeName, TypeName: typeDef.TypeName}, nil
 	}
 
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
 
+	if ref, ok := g.typeRefs[t]; ok {
+		return SchemaObj{Ref: ref, TypeName: t.Name()}, nil
+	}
+
 	switch t.Kind() {
 	case reflect.Struct:
 		if typeDef, found := g.getDefinition(t); found {
 			return typeDef.Export(), nil
 		}
 
-		typeDef = *NewSchemaObj("object", ReflectTypeReliableName(t))
+		if t.Name() == "" && typeName == "" && g.hoistAnonymous {
+			defer g.parseDefInQueueKeepError(ctx, &err)
+			return g.genSchemaForAnonymous(t), nil
+		}
+
+		// anonymous structs are returned in-place unless they are mapped from a named type
+		name := t.Name()
+		if name == "" {
+			name = typeName
+		}
+
+		typeDef = *g.newSchemaObj("object", name)
+		typeDef.Description, _ = g.docComment(t, "")
 		typeDef.Properties = g.parseDefinitionProperties(v, &typeDef)
 		if typeDef.TypeName == "" {
 			typeDef.TypeName = typeName
 		}
 
-		// if len(typeDef.Properties) == 0 {
-		//	typeDef.Ref = ""
-		// }
+		if r, ok := reflect.New(t).Interface().(IRequired); ok {
+			for _, propName := range r.SwgenRequired() {
+				if _, ok := typeDef.Properties[propName]; !ok {
+					return typeDef, fmt.Errorf("required property %s of %s is not defined", propName, t.String())
+				}
+				typeDef.addRequired(propName)
+			}
+		}
+
+		// empty object is valid, but usually fields are unexported or not tagged by mistake
+		if len(typeDef.Properties) == 0 && typeDef.TypeName != "" {
+			g.warnf("%s: definition %s has no properties", t.String(), typeDef.TypeName)
+		}
 	case reflect.Slice, reflect.Array:
 		elemType := t.Elem()
 		if elemType.Kind() == reflect.Ptr {
@@ -170,14 +351,14 @@ func (g *Generator) ParseDefinition(i interface{}) (schema SchemaObj, err error)
 		}
 
 		var itemSchema SchemaObj
-		if elemType.Kind() != reflect.Struct || (elemType.Kind() == reflect.Struct && elemType.Name() != "") {
+		if elemType.Kind() != reflect.Struct || elemType.Name() != "" || g.hoistAnonymous {
 			itemSchema = g.genSchemaForType(elemType)
 		} else {
-			itemSchema = *NewSchemaObj("object", elemType.Name())
-			itemSchema.Properties = g.parseDefinitionProperties(v.Elem(), &itemSchema)
+			itemSchema = *g.newSchemaObj("object", elemType.Name())
+			itemSchema.Properties = g.parseDefinitionProperties(reflect.Zero(elemType), &itemSchema)
 		}
 
-		typeDef = *NewSchemaObj("array", t.Name())
+		typeDef = *g.newSchemaObj("array", t.Name())
 		typeDef.Items = &itemSchema
 		if typeDef.TypeName == "" {
 			typeDef.TypeName = typeName
@@ -192,7 +373,7 @@ func (g *Generator) ParseDefinition(i interface{}) (schema SchemaObj, err error)
 			return typeDef.Export(), nil
 		}
 
-		typeDef = *NewSchemaObj("object", t.Name())
+		typeDef = *g.newSchemaObj("object", t.Name())
 		itemDef := g.genSchemaForType(elemType)
 		typeDef.AdditionalProperties = &itemDef
 		if typeDef.TypeName == "" {
@@ -201,30 +382,52 @@ func (g *Generator) ParseDefinition(i interface{}) (schema SchemaObj, err error)
 	default:
 		typeDef = g.genSchemaForType(t)
 		typeDef.TypeName = typeDef.Type
+		if e, isEnumer := reflect.Zero(t).Interface().(enumer); isEnumer {
+			typeDef.Enum, typeDef.EnumVarNames = e.GetEnumSlices()
+		}
 		return typeDef, nil
 	}
 
-	defer g.parseDefInQueue()
+	defer g.parseDefInQueueKeepError(ctx, &err)
 
 	if g.reflectGoTypes {
-		typeDef.GoType = goType(t)
+		typeDef.GoType = g.goType(t)
 	}
 
 	if typeDef.TypeName != "" { // non-anonymous types should be added to definitions map and returned "in-place" as references
-		g.addDefinition(t, &typeDef)
+		if err = g.addDefinition(t, &typeDef); err != nil {
+			return typeDef, err
+		}
 		return typeDef.Export(), nil
 	}
 	return typeDef, nil // anonymous types are not added to definitions map; instead, they are returned "in-place" in full form
 }
 
+// goType returns goType of t memoized by generator
+func (g *Generator) goType(t reflect.Type) string {
+	g.goTypesMu.Lock()
+	defer g.goTypesMu.Unlock()
+
+	s, ok := g.goTypes[t]
+	if !ok {
+		s = goType(t)
+		g.goTypes[t] = s
+	}
+	return s
+}
+
+// importPath returns import path of package of named type t without vendor directory prefix
+func importPath(t reflect.Type) string {
+	pkgPath := t.PkgPath()
+	if pos := strings.Index(pkgPath, "/vendor/"); pos != -1 {
+		pkgPath = pkgPath[pos+8:]
+	}
+	return pkgPath
+}
+
 func goType(t reflect.Type) (s string) {
 	s = t.Name()
-	pkgPath := t.PkgPath()
-	if pkgPath != "" {
-		pos := strings.Index(pkgPath, "/vendor/")
-		if pos != -1 {
-			pkgPath = pkgPath[pos+8:]
-		}
+	if pkgPath := importPath(t); pkgPath != "" {
 		s = pkgPath + "." + s
 	}
 
@@ -252,26 +455,90 @@ func goType(t reflect.Type) (s string) {
 	return
 }
 
+// goPackage returns import path of package of named type t or of element type of unnamed t, e.g. of []*T,
+// empty string is returned for predeclared and unnamed types and for types of standard library,
+// their packages depend on Go version, e.g. of internal types
+func goPackage(t reflect.Type) string {
+	for t.Name() == "" && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+		t = t.Elem()
+	}
+
+	pkgPath := importPath(t)
+	if pkgPath == "" || isStandardPackage(pkgPath) {
+		return ""
+	}
+	return pkgPath
+}
+
+var (
+	standardPackagesMu sync.Mutex      // mutex for standardPackages
+	standardPackages   map[string]bool // memoized results of isStandardPackage
+)
+
+// isStandardPackage reports whether package with import path pkgPath is found in GOROOT,
+// paths without dot are not checked by their form as they are valid for user modules too
+func isStandardPackage(pkgPath string) bool {
+	standardPackagesMu.Lock()
+	defer standardPackagesMu.Unlock()
+
+	if standard, ok := standardPackages[pkgPath]; ok {
+		return standard
+	}
+
+	pkg, err := build.Default.Import(pkgPath, "", build.FindOnly)
+	standard := err == nil && pkg.Goroot
+	if standardPackages == nil {
+		standardPackages = make(map[string]bool)
+	}
+	standardPackages[pkgPath] = standard
+	return standard
+}
+
 func (g *Generator) parseDefinitionProperties(v reflect.Value, parent *SchemaObj) map[string]SchemaObj {
 	if v.Kind() == reflect.Ptr {
 		v = v.Elem()
 	}
 	t := v.Type()
 	properties := make(map[string]SchemaObj, t.NumField())
-	if g.reflectGoTypes && parent.GoPropertyNames == nil {
+	if g.reflectGoNames && parent.GoPropertyNames == nil {
 		parent.GoPropertyNames = make(map[string]string, t.NumField())
+	}
+	if g.reflectGoTypes && parent.GoPropertyTypes == nil {
 		parent.GoPropertyTypes = make(map[string]string, t.NumField())
 	}
 
+	// path of fields being parsed is kept for panic messages, it starts with the path
+	// a queued type was referenced by or with type name
+	depth := len(g.parsePath)
+	defer func() { g.parsePath = g.parsePath[:depth] }()
+	if depth == 0 {
+		if path, ok := g.defPaths[t]; ok {
+			g.parsePath = append(g.parsePath, path)
+		} else if t.Name() != "" {
+			g.parsePath = append(g.parsePath, t.Name())
+		}
+	}
+	fieldDepth := len(g.parsePath)
+
 	for i := 0; i < t.NumField(); i = i + 1 {
 		field := t.Field(i)
+		g.parsePath = append(g.parsePath[:fieldDepth], field.Name)
+
+		// title may be set on any field, usually on a blank marker one
+		if title := field.Tag.Get("swgen_title"); title != "" {
+			parent.Title = title
+		}
 
 		// we can't access the value of un-exportable field
 		if field.PkgPath != "" {
+			if tag, ok := field.Tag.Lookup(g.fieldTag); ok && tag != "-" && field.Name != "_" && g.warnUnexportedTagged {
+				g.warnf("%s.%s: unexported field has %s tag, skipped", t.String(), field.Name, g.fieldTag)
+			}
 			continue
 		}
 
-		if field.Anonymous {
+		// like in encoding/json, embedded interface is a regular field named by its type unless it is tagged
+		if field.Anonymous && field.Type.Kind() != reflect.Interface {
 			fieldProperties := g.parseDefinitionProperties(v.Field(i), parent)
 			for propertyName, property := range fieldProperties {
 				properties[propertyName] = property
@@ -279,38 +546,107 @@ func (g *Generator) parseDefinitionProperties(v reflect.Value, parent *SchemaObj
 			continue
 		}
 
-		// don't check if it's omitted
-		var tag string
-		if tag = field.Tag.Get("json"); tag == "-" || tag == "" {
+		tag := field.Tag.Get(g.fieldTag)
+		if tag == "-" {
 			continue
 		}
 
-		propName := strings.Split(tag, ",")[0]
+		var propName string
+		if g.propertyNamer != nil {
+			propName = g.propertyNamer(field)
+		}
+		if propName == "" {
+			// don't check if it's omitted
+			if tag == "" && !field.Anonymous {
+				continue
+			}
+			propName = strings.Split(tag, ",")[0]
+			// like encoding/json, use field name if tag only has options
+			if propName == "" {
+				propName = field.Name
+			}
+		}
 		var (
 			obj SchemaObj
 		)
 
+		if field.Tag.Get("swgen_type") == "" && !isSupportedKind(field.Type) {
+			g.warnf("%s.%s: field of unsupported type %s skipped", t.String(), field.Name, field.Type.String())
+			continue
+		}
+
+		if _, registered := g.interfaceImpls[field.Type]; field.Anonymous && !registered &&
+			field.Type.NumMethod() > 0 && !v.Field(i).Elem().IsValid() && field.Tag.Get("swgen_type") == "" {
+			g.warnf("%s.%s: embedded interface without registered implementations skipped", t.String(), field.Name)
+			continue
+		}
+
 		if dataType := field.Tag.Get("swgen_type"); dataType != "" {
-			obj = SchemaFromCommonName(commonName(dataType))
+			obj = g.schemaFromCommonName(dataType)
 		} else {
-			if field.Type.Kind() == reflect.Interface && v.Field(i).Elem().IsValid() {
+			if _, registered := g.interfaceImpls[field.Type]; !registered &&
+				field.Type.Kind() == reflect.Interface && v.Field(i).Elem().IsValid() {
 				obj = g.genSchemaForType(v.Field(i).Elem().Type())
 			} else {
 				obj = g.genSchemaForType(field.Type)
 			}
 		}
 
+		g.applyFormatHeuristics(field, &obj)
+		if format := field.Tag.Get("swgen_format"); format != "" && obj.Ref == "" {
+			obj.Format = format
+		}
+
+		if g.nullablePointers && field.Type.Kind() == reflect.Ptr && !obj.Nullable {
+			obj = nullableSchema(obj)
+		}
+
 		if defaultTag := field.Tag.Get("default"); defaultTag != "" {
 			if defaultValue, err := g.caseDefaultValue(field.Type, defaultTag); err == nil {
 				obj.Default = defaultValue
+			} else {
+				g.warnf("%s: invalid default %q skipped: %v", strings.Join(g.parsePath, "."), defaultTag, err)
 			}
 		}
+		if boolTag(field, "deprecated") {
+			obj.Deprecated = true
+		}
+
+		enumType := field.Type
+		for enumType.Kind() == reflect.Ptr {
+			enumType = enumType.Elem()
+		}
+		if e, isEnumer := reflect.Zero(enumType).Interface().(enumer); isEnumer {
+			obj.Enum, obj.EnumVarNames = e.GetEnumSlices()
+		}
+
+		if doc, ok := g.docComment(t, field.Name); ok {
+			obj.Description = doc
+		}
+
+		parseSchemaConstraints(field, &obj)
+		parseExtensionsTag(field, &obj.additionalData)
+
+		if boolTag(field, "discriminator") {
+			parent.setDiscriminator(propName)
+		} else if g.isRequiredProperty(field, tag) {
+			parent.addRequired(propName)
+		}
+
+		if g.reflectGoNames {
+			parent.GoPropertyNames[propName] = field.Name
+		}
 		if g.reflectGoTypes {
-			if obj.Ref == "" {
-				obj.GoType = goType(field.Type)
+			if obj.Ref == "" && obj.AllOf == nil {
+				obj.GoType = g.goType(field.Type)
+				obj.GoPackage = goPackage(field.Type)
 			}
-			parent.GoPropertyNames[propName] = field.Name
-			parent.GoPropertyTypes[propName] = goType(field.Type)
+			parent.GoPropertyTypes[propName] = g.goType(field.Type)
+		}
+
+		if g.emitPropertyOrder {
+			obj.AddExtendedField("x-order", parent.propertyOrder)
+			parent.propertyOrder++
 		}
 
 		properties[propName] = obj
@@ -329,7 +665,7 @@ func (g *Generator) caseDefaultValue(t reflect.Type, defaultValue string) (inter
 	switch kind {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return strconv.ParseInt(defaultValue, 10, 64)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 		return strconv.ParseUint(defaultValue, 10, 64)
 	case reflect.Float32, reflect.Float64:
 		return strconv.ParseFloat(defaultValue, 64)
@@ -346,19 +682,179 @@ func (g *Generator) caseDefaultValue(t reflect.Type, defaultValue string) (inter
 	}
 }
 
+// isRequiredProperty checks if property of field with tag of property name is required,
+// required tag takes precedence over inference from omitempty option
+func (g *Generator) isRequiredProperty(field reflect.StructField, tag string) bool {
+	if required, err := strconv.ParseBool(field.Tag.Get("required")); err == nil {
+		return required
+	}
+	return g.inferRequired && !Contains(strings.Split(tag, ",")[1:], "omitempty")
+}
+
+// parseSchemaConstraints sets validation keywords of obj from field tags
+func parseSchemaConstraints(field reflect.StructField, obj *SchemaObj) {
+	switch obj.Type {
+	case "string":
+		if pattern := field.Tag.Get("pattern"); pattern != "" {
+			obj.Pattern = pattern
+		}
+	case "array":
+		obj.MinItems = intTag(field, "minItems")
+		obj.MaxItems = intTag(field, "maxItems")
+		obj.UniqueItems = boolTag(field, "uniqueItems")
+	case "integer", "number":
+		obj.MultipleOf = floatTag(field, "multipleOf")
+		if obj.Minimum = floatTag(field, "minimum"); obj.Minimum != nil {
+			obj.ExclusiveMinimum = boolTag(field, "exclusiveMinimum")
+		}
+		if obj.Maximum = floatTag(field, "maximum"); obj.Maximum != nil {
+			obj.ExclusiveMaximum = boolTag(field, "exclusiveMaximum")
+		}
+	}
+}
+
+// intTag returns integer value of field tag with given name, nil if tag is missing or invalid
+func intTag(field reflect.StructField, name string) *int {
+	i, err := strconv.Atoi(field.Tag.Get(name))
+	if err != nil {
+		return nil
+	}
+	return &i
+}
+
+// floatTag returns float value of field tag with given name, nil if tag is missing or invalid
+func floatTag(field reflect.StructField, name string) *float64 {
+	f, err := strconv.ParseFloat(field.Tag.Get(name), 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// applyFormatHeuristics sets format of string schema without format by field name or registered type
+// if format heuristics are enabled: *Email fields are emails, *URL fields are URIs and registered types are UUIDs,
+// registered types of other kinds, e.g. [16]byte, are described as uuid strings too
+func (g *Generator) applyFormatHeuristics(field reflect.StructField, obj *SchemaObj) {
+	if !g.formatHeuristics || obj.Format != "" || obj.Ref != "" || field.Tag.Get("swgen_type") != "" {
+		return
+	}
+
+	t := field.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if g.uuidTypes[t] {
+		obj.Type, obj.Format, obj.Items = "string", "uuid", nil
+		return
+	}
+	if obj.Type != "string" {
+		return
+	}
+
+	switch {
+	case strings.HasSuffix(field.Name, "Email"):
+		obj.Format = "email"
+	case strings.HasSuffix(field.Name, "URL"):
+		obj.Format = "uri"
+	}
+}
+
+// parseExtensionsTag adds vendor extensions from swgen_ext tag, e.g. `swgen_ext:"x-order=3,x-nullable=true"`,
+// values that are not valid JSON are added as strings, commas inside JSON arrays, objects and strings
+// do not separate extensions, e.g. `swgen_ext:"x-tags=[\"a\",\"b\"],x-order=3"`
+func parseExtensionsTag(field reflect.StructField, ad *additionalData) {
+	tag := field.Tag.Get("swgen_ext")
+	if tag == "" {
+		return
+	}
+
+	for _, ext := range splitExtensionsTag(tag) {
+		kv := strings.SplitN(ext, "=", 2)
+		name := strings.TrimSpace(kv[0])
+		if name == "" {
+			continue
+		}
+
+		var value interface{} = true
+		if len(kv) == 2 {
+			if err := json.Unmarshal([]byte(kv[1]), &value); err != nil {
+				value = kv[1]
+			}
+		}
+		ad.AddExtendedField(name, value)
+	}
+}
+
+// splitExtensionsTag splits tag by commas that are not inside brackets, braces or quoted strings
+func splitExtensionsTag(tag string) []string {
+	var (
+		exts    []string
+		depth   int
+		quoted  bool
+		escaped bool
+		start   int
+	)
+	for i, r := range tag {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '[' || r == '{':
+			depth++
+		case r == ']' || r == '}':
+			depth--
+		case r == ',' && depth == 0:
+			exts = append(exts, tag[start:i])
+			start = i + 1
+		}
+	}
+	return append(exts, tag[start:])
+}
+
+// boolTag reports whether field has tag with given name set to a true value
+func boolTag(field reflect.StructField, name string) bool {
+	b, err := strconv.ParseBool(field.Tag.Get(name))
+	return err == nil && b
+}
+
 // ParseDefinition create a DefObj from input object, it should be a pointer to a struct,
 // it reuse schema/json tag for property name.
 func ParseDefinition(i interface{}) (typeDef SchemaObj, err error) {
 	return gen.ParseDefinition(i)
 }
 
-func (g *Generator) parseDefInQueue() {
+// ParseDefinitionCtx is ParseDefinition that stops parsing of queued definitions once ctx is done
+func ParseDefinitionCtx(ctx context.Context, i interface{}) (typeDef SchemaObj, err error) {
+	return gen.ParseDefinitionCtx(ctx, i)
+}
+
+func (g *Generator) parseDefInQueue(ctx context.Context) error {
 	if len(g.defQueue) == 0 {
-		return
+		return nil
 	}
 
 	for t := range g.defQueue {
-		g.ParseDefinition(reflect.Zero(t).Interface())
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		delete(g.defQueue, t)
+		if _, err := g.parseDefinition(ctx, reflect.Zero(t).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseDefInQueueKeepError parses queued definitions setting err unless it already holds an error
+func (g *Generator) parseDefInQueueKeepError(ctx context.Context, err *error) {
+	if queueErr := g.parseDefInQueue(ctx); queueErr != nil && *err == nil {
+		*err = queueErr
 	}
 }
 
@@ -368,13 +864,53 @@ func (g *Generator) genSchemaForType(t reflect.Type) SchemaObj {
 	}
 
 	smObj := SchemaObj{TypeName: t.Name()}
+	if ref, ok := g.typeRefs[t]; ok {
+		smObj.Ref = ref
+		return smObj
+	}
+
+	if tf, ok := g.typeFormats[t]; ok {
+		smObj.Type, smObj.Format = tf.Type, tf.Format
+		if g.reflectGoTypes {
+			smObj.GoType = g.goType(t)
+		}
+		return smObj
+	}
+
+	if nullable, ok := reflect.Zero(t).Interface().(INullable); ok {
+		if value := nullable.SwgenUnwrap(); value != nil {
+			return nullableSchema(g.genSchemaForType(reflect.TypeOf(value)))
+		}
+	}
+
+	// custom definitions of any kind are referenced, otherwise arrays and primitives would be inlined
+	// with schema of their kind instead of the one returned by SwgenDefinition
+	if definition, ok := reflect.Zero(t).Interface().(IDefinition); ok {
+		if typeDef, found := g.getDefinition(t); found {
+			smObj.Ref = g.refPrefix + typeDef.TypeName
+		} else {
+			typeName, _, _ := definition.SwgenDefinition()
+			if typeName == "" {
+				typeName = t.Name()
+			}
+			smObj.Ref = g.refPrefix + definitionName(typeName)
+			g.addToDefQueue(t)
+		}
+		return smObj
+	}
 
 	switch t.Kind() {
 	case reflect.Bool:
 		smObj = SchemaFromCommonName(CommonNameBoolean)
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16:
+	case reflect.Int, reflect.Uint:
+		if g.intWidth == 64 {
+			smObj = SchemaFromCommonName(CommonNameLong)
+		} else {
+			smObj = SchemaFromCommonName(CommonNameInteger)
+		}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
 		smObj = SchemaFromCommonName(CommonNameInteger)
-	case reflect.Int64, reflect.Uint32, reflect.Uint64:
+	case reflect.Int64, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 		smObj = SchemaFromCommonName(CommonNameLong)
 	case reflect.Float32:
 		smObj = SchemaFromCommonName(CommonNameFloat)
@@ -383,48 +919,157 @@ func (g *Generator) genSchemaForType(t reflect.Type) SchemaObj {
 	case reflect.String:
 		smObj = SchemaFromCommonName(CommonNameString)
 	case reflect.Array, reflect.Slice:
-		if t != typeOfJSONRawMsg {
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && t != typeOfJSONRawMsg {
+			// encoding/json marshals []byte as base64 string
+			smObj = SchemaFromCommonName(CommonNameByte)
+		} else if t != typeOfJSONRawMsg {
 			smObj.Type = "array"
 			itemSchema := g.genSchemaForType(t.Elem())
 			smObj.Items = &itemSchema
 		}
 	case reflect.Map:
-		smObj.Type = "object"
-		itemSchema := g.genSchemaForType(t.Elem())
-		smObj.AdditionalProperties = &itemSchema
+		if t.Name() != "" {
+			// named maps are shared like structs, so they are referenced
+			g.setDefinitionRef(t, &smObj)
+		} else {
+			smObj.Type = "object"
+			itemSchema := g.genSchemaForType(t.Elem())
+			smObj.AdditionalProperties = &itemSchema
+		}
 	case reflect.Struct:
 		switch {
-		case t == typeOfTime:
+		case t == typeOfTime || t.ConvertibleTo(typeOfTime):
+			// types defined as time.Time, e.g. type Date time.Time, are convertible to it
 			smObj = SchemaFromCommonName(CommonNameDateTime)
-		case reflect.PtrTo(t).Implements(typeOfTextUnmarshaler):
+		case t == typeOfBigInt:
+			smObj.Type = "integer"
+		case t == typeOfBigFloat:
+			smObj.Type = "number"
+		case reflect.PtrTo(t).Implements(typeOfTextUnmarshaler), reflect.PtrTo(t).Implements(typeOfTextMarshaler):
 			smObj.Type = "string"
+		case t.Name() == "" && g.hoistAnonymous:
+			smObj = g.genSchemaForAnonymous(t)
+		case t.Name() == "":
+			// anonymous structs have no name to be referenced with, so they are inlined
+			smObj.Type = "object"
+			smObj.Properties = g.parseDefinitionProperties(reflect.Zero(t), &smObj)
 		default:
-			name := ReflectTypeReliableName(t)
-			smObj.Ref = refDefinitionPrefix + name
-			if !g.defExists(t) || !g.defInQueue(t) {
-				g.addToDefQueue(t)
-			}
+			g.setDefinitionRef(t, &smObj)
 		}
 	case reflect.Interface:
-		if t.NumMethod() > 0 {
-			panic("Non-empty interface is not supported: " + t.String())
+		if impls, ok := g.interfaceImpls[t]; ok {
+			smObj = g.genSchemaForInterface(t, impls)
+		} else if t.NumMethod() > 0 {
+			g.panicf("Non-empty interface is not supported: %s", t.String())
 		}
+		// empty interface is described with empty schema that allows any value
 	default:
-		panic(fmt.Sprintf("type %s is not supported: %s", t.Kind(), t.String()))
+		g.panicf("type %s is not supported: %s", t.Kind(), t.String())
 	}
 
 	if g.reflectGoTypes && smObj.Ref == "" {
-		smObj.GoType = goType(t)
+		smObj.GoType = g.goType(t)
 	}
 
 	return smObj
 }
 
+// setDefinitionRef sets reference to definition of t, queueing the definition if it is not parsed yet
+func (g *Generator) setDefinitionRef(t reflect.Type, smObj *SchemaObj) {
+	if typeDef, found := g.getDefinition(t); found {
+		smObj.Ref = g.refPrefix + typeDef.TypeName
+		return
+	}
+
+	smObj.Ref = g.refPrefix + ReflectTypeReliableName(t)
+	g.addToDefQueue(t)
+	if _, ok := g.defPaths[t]; !ok && len(g.parsePath) > 0 {
+		g.defPaths[t] = strings.Join(g.parsePath, ".")
+	}
+}
+
+// nullableSchema marks schema as nullable, $ref can not have sibling keywords, so it is wrapped with allOf
+func nullableSchema(obj SchemaObj) SchemaObj {
+	if obj.Ref != "" {
+		obj = SchemaObj{AllOf: []SchemaObj{{Ref: obj.Ref}}, TypeName: obj.TypeName}
+	}
+	obj.Nullable = true
+	return obj
+}
+
+// isSupportedKind checks that t, or its element type for pointers and containers, can be described with schema,
+// channels, functions, complex numbers and unsafe pointers can not
+func isSupportedKind(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return false
+	}
+	return true
+}
+
+// panicf panics with message prefixed with path of the field being parsed, e.g. "User.Address.Location: ..."
+func (g *Generator) panicf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if len(g.parsePath) > 0 {
+		msg = strings.Join(g.parsePath, ".") + ": " + msg
+	}
+	panic(msg)
+}
+
+// genSchemaForInterface adds definition of interface type listing its implementations and returns reference to it
+func (g *Generator) genSchemaForInterface(t reflect.Type, impls []reflect.Type) SchemaObj {
+	if !g.defExists(t) {
+		typeDef := *g.newSchemaObj("object", ReflectTypeReliableName(t))
+		for _, impl := range impls {
+			typeDef.OneOf = append(typeDef.OneOf, g.genSchemaForType(impl))
+		}
+		if g.reflectGoTypes {
+			typeDef.GoType = g.goType(t)
+		}
+		g.addDefinition(t, &typeDef)
+	}
+
+	typeDef, _ := g.getDefinition(t)
+	return typeDef.Export()
+}
+
+// genSchemaForAnonymous adds definition of anonymous struct with synthesized name and returns reference to it,
+// identical anonymous structs have the same reflect.Type and so share the definition
+func (g *Generator) genSchemaForAnonymous(t reflect.Type) SchemaObj {
+	if !g.defExists(t) {
+		typeDef := SchemaObj{Type: "object"}
+		typeDef.Properties = g.parseDefinitionProperties(reflect.Zero(t), &typeDef)
+
+		// name is chosen after properties are parsed as nested anonymous structs take names first
+		name := ""
+		for i := 1; name == "" || g.definitionAdded[name]; i++ {
+			name = fmt.Sprintf("Inline%d", i)
+		}
+
+		typeDef.TypeName = name
+		typeDef.Ref = g.refPrefix + name
+		if g.reflectGoTypes {
+			typeDef.GoType = g.goType(t)
+		}
+		g.addDefinition(t, &typeDef)
+	}
+
+	typeDef, _ := g.getDefinition(t)
+	return typeDef.Export()
+}
+
 //
 // Parse struct to swagger parameter object of operation object
 // see http://swagger.io/specification/#parameterObject
 //
 
+// paramLocations lists allowed values of parameter location
+var paramLocations = []string{"query", "header", "path", "formData", "body"}
+
 // ParseParameter parse input struct to swagger parameter object
 func (g *Generator) ParseParameter(i interface{}) (name string, params []ParamObj, err error) {
 	if param, ok := i.(IParameter); ok {
@@ -451,7 +1096,7 @@ func (g *Generator) ParseParameter(i interface{}) (name string, params []ParamOb
 	name = t.Name()
 	params = []ParamObj{}
 
-	ForEachField(i, func(field reflect.StructField, value interface{}) bool {
+	forEachField(i, "", func(field reflect.StructField, value interface{}, prefix string) bool {
 
 		// // we can't access the value of un-exportable or anonymous fields
 		// if field.PkgPath != "" || field.Anonymous {
@@ -477,12 +1122,17 @@ func (g *Generator) ParseParameter(i interface{}) (name string, params []ParamOb
 
 		paramName := strings.Split(nameTag, ",")[0]
 		param := ParamObj{}
-		if g.reflectGoTypes {
+		if g.reflectGoNames {
 			param.AddExtendedField("x-go-name", field.Name)
-			param.AddExtendedField("x-go-type", goType(field.Type))
+		}
+		if g.reflectGoTypes {
+			param.AddExtendedField("x-go-type", g.goType(field.Type))
+			if pkgPath := goPackage(field.Type); pkgPath != "" {
+				param.AddExtendedField("x-go-package", pkgPath)
+			}
 		}
 
-		param.Name = paramName
+		param.Name = prefix + paramName
 
 		if e, isEnumer := reflect.Zero(field.Type).Interface().(enumer); isEnumer {
 			param.Enum.Enum, param.Enum.EnumNames = e.GetEnumSlices()
@@ -490,8 +1140,20 @@ func (g *Generator) ParseParameter(i interface{}) (name string, params []ParamOb
 
 		if descTag := field.Tag.Get("description"); descTag != "-" && descTag != "" {
 			param.Description = descTag
+		} else if descTag == "" {
+			param.Description = g.paramDescriptions[field.Name]
 		}
 
+		if defaultTag := field.Tag.Get("default"); defaultTag != "" {
+			if defaultValue, err := g.caseDefaultValue(field.Type, defaultTag); err == nil {
+				param.Default = defaultValue
+			} else {
+				g.warnf("%s.%s: invalid default %q skipped: %v", name, field.Name, defaultTag, err)
+			}
+		}
+
+		param.Deprecated = boolTag(field, "deprecated")
+
 		binding := field.Tag.Get("binding")
 		bindings := strings.Split(binding, ";")
 
@@ -501,17 +1163,41 @@ func (g *Generator) ParseParameter(i interface{}) (name string, params []ParamOb
 			param.Required = false
 		}
 
+		// explicit required tag overrides binding
+		if required, err := strconv.ParseBool(field.Tag.Get("required")); err == nil {
+			param.Required = required
+		}
+
 		if inTag := field.Tag.Get("in"); inTag != "-" && inTag != "" {
-			param.In = inTag // todo: validate IN value
+			if !Contains(paramLocations, inTag) {
+				err = fmt.Errorf("Generator.ParseParameter() failed: invalid in tag %q of field %s.%s", inTag, name, field.Name)
+				return false
+			}
+			param.In = inTag
 		} else if inPath {
 			param.In = "path"
 		} else {
 			param.In = "query"
 		}
 
+		// path parameters are always required by specification
+		if param.In == "path" {
+			param.Required = true
+		}
+
+		// empty value is allowed only for parameters in query or formData
+		if param.In == "query" || param.In == "formData" {
+			param.AllowEmptyValue = boolTag(field, "allowEmptyValue")
+		}
+
+		if field.Tag.Get("swgen_type") == "" && !isSupportedKind(field.Type) {
+			g.warnf("%s.%s: field of unsupported type %s skipped", name, field.Name, field.Type.String())
+			return true
+		}
+
 		var schema SchemaObj
 		if swGenType := field.Tag.Get("swgen_type"); swGenType != "" {
-			schema = SchemaFromCommonName(commonName(swGenType))
+			schema = g.schemaFromCommonName(swGenType)
 		} else {
 			if mappedTo, ok := g.getMappedType(field.Type); ok {
 				schema = g.genSchemaForType(reflect.TypeOf(mappedTo))
@@ -524,19 +1210,41 @@ func (g *Generator) ParseParameter(i interface{}) (name string, params []ParamOb
 			panic("dont support struct " + v.Type().Name() + " in property " + field.Name + " of parameter struct")
 		}
 
+		g.applyFormatHeuristics(field, &schema)
+		if format := field.Tag.Get("swgen_format"); format != "" {
+			schema.Format = format
+		}
+
+		parseSchemaConstraints(field, &schema)
+
 		param.Type = schema.Type
 		param.Format = schema.Format
+		param.Pattern = schema.Pattern
+		param.MinItems = schema.MinItems
+		param.MaxItems = schema.MaxItems
+		param.UniqueItems = schema.UniqueItems
+		param.MultipleOf = schema.MultipleOf
+		param.Minimum = schema.Minimum
+		param.ExclusiveMinimum = schema.ExclusiveMinimum
+		param.Maximum = schema.Maximum
+		param.ExclusiveMaximum = schema.ExclusiveMaximum
 
 		if schema.Type == "array" && schema.Items != nil {
 			if schema.Items.Ref != "" || schema.Items.Type == "array" {
-				panic("dont support array of struct or nested array in parameter")
+				g.warnf("%s.%s: array of struct or nested array is not supported in parameter, skipped", name, field.Name)
+				return true
 			}
 
 			param.Items = &ParamItemObj{
 				Type:   schema.Items.Type,
 				Format: schema.Items.Format,
 			}
-			param.CollectionFormat = "multi" // default for now
+			// multi is valid only for parameters in query or formData
+			if param.In == "query" || param.In == "formData" {
+				param.CollectionFormat = "multi"
+			} else {
+				param.CollectionFormat = "csv"
+			}
 		}
 
 		params = append(params, param)
@@ -632,14 +1340,28 @@ func ParseParameter(i interface{}) (name string, params []ParamObj, err error) {
 	return gen.ParseParameter(i)
 }
 
+// ForEachField calls f for every exported field of struct o in declaration order until f returns false.
+// Embedded structs are flattened the same way definitions handle them: f is called for the fields
+// of an embedded struct instead of the embedded field itself, embedded nil pointers to struct are skipped.
+// Other struct fields are passed to f as is.
 func ForEachField(o interface{}, f func(field reflect.StructField, value interface{}) bool) {
+	forEachField(o, "", func(field reflect.StructField, value interface{}, _ string) bool {
+		return f(field, value)
+	})
+}
+
+// forEachField passes to f the name prefix accumulated from prefix tags of embedded structs
+func forEachField(o interface{}, prefix string, f func(field reflect.StructField, value interface{}, prefix string) bool) bool {
 	if o == nil {
-		return
+		return true
 	}
 
 	v := reflect.ValueOf(o)
 
 	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return true
+		}
 		v = v.Elem()
 	}
 
@@ -649,25 +1371,32 @@ func ForEachField(o interface{}, f func(field reflect.StructField, value interfa
 		tf := t.Field(i)
 		vf := v.Field(i)
 
-		// 不包括私有字段（私有字段不在递归）
-		if !IsCapitalHeader(tf.Name) {
+		// we can't access the value of un-exportable field
+		if tf.PkgPath != "" {
 			continue
 		}
 
-		if tf.Type.Kind() == reflect.Ptr {
-			if tf.Type.Elem().Kind() == reflect.Struct {
-				vok := reflect.New(tf.Type.Elem()).Interface()
-				ForEachField(vok, f)
+		if tf.Anonymous {
+			switch {
+			case tf.Type.Kind() == reflect.Ptr && tf.Type.Elem().Kind() == reflect.Struct:
+				if !vf.IsNil() && !forEachField(vf.Interface(), prefix+tf.Tag.Get("prefix"), f) {
+					return false
+				}
+				continue
+			case tf.Type.Kind() == reflect.Struct:
+				if !forEachField(vf.Interface(), prefix+tf.Tag.Get("prefix"), f) {
+					return false
+				}
+				continue
 			}
-		} else if tf.Type.Kind() == reflect.Struct {
-			ForEachField(vf.Interface(), f)
 		}
 
-		success := f(tf, vf.Interface())
-		if !success {
-			return
+		if !f(tf, vf.Interface(), prefix) {
+			return false
 		}
 	}
+
+	return true
 }
 
 // 是否为大写开头
@@ -695,6 +1424,7 @@ func Contains(list []string, s string) bool {
 
 // ResetPaths remove all current paths
 func (g *Generator) ResetPaths() {
+	g.invalidateCache()
 	g.paths = make(map[string]PathItem)
 }
 
@@ -703,28 +1433,120 @@ func ResetPaths() {
 	gen.ResetPaths()
 }
 
-var regexFindPathParameter = regexp.MustCompile(`\{([^}:]+)(:[^\/]+)?(?:\})`)
+// RemovePath removes path item with all its operations, path may be a template of supported routers like in SetPathItem
+func (g *Generator) RemovePath(path string) {
+	path, _ = normalizePath(path)
+	delete(g.paths, path)
+	g.invalidateCache()
+}
+
+// RemovePath removes path item with all its operations
+func RemovePath(path string) {
+	gen.RemovePath(path)
+}
+
+// RemovePathMethod removes operation of path item by HTTP method, path item without operations is removed
+func (g *Generator) RemovePathMethod(path, method string) {
+	path, _ = normalizePath(path)
+	item, found := g.paths[path]
+	if !found {
+		return
+	}
+
+	item.setOperation(method, nil)
+	if item.isEmpty() {
+		delete(g.paths, path)
+	} else {
+		g.paths[path] = item
+	}
+	g.invalidateCache()
+}
+
+// RemovePathMethod removes operation of path item by HTTP method, path item without operations is removed
+func RemovePathMethod(path, method string) {
+	gen.RemovePathMethod(path, method)
+}
+
+// normalizePath converts path templates of common routers to Swagger one and returns names of path parameters:
+// regular expressions of gorilla/mux and chi parameters are removed ({id:[0-9]+} becomes {id}),
+// echo and httprouter parameters become templated ({id} for :id, {name} for *name and {wildcard} for *)
+func normalizePath(path string) (string, []string) {
+	var (
+		buf        bytes.Buffer
+		parameters []string
+	)
+
+	for i := 0; i < len(path); i++ {
+		if path[i] != '{' {
+			buf.WriteByte(path[i])
+			continue
+		}
+
+		// find matching brace, regular expression may contain quantifiers like {3}
+		depth, end := 0, -1
+		for j := i; j < len(path) && end == -1; j++ {
+			switch path[j] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					end = j
+				}
+			}
+		}
+		if end == -1 {
+			buf.WriteString(path[i:])
+			break
+		}
+
+		name := strings.SplitN(path[i+1:end], ":", 2)[0]
+		parameters = append(parameters, name)
+		buf.WriteString("{" + name + "}")
+		i = end
+	}
+
+	segments := strings.Split(buf.String(), "/")
+	for i, segment := range segments {
+		var name string
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			name = segment[1:]
+		case segment == "*":
+			name = "wildcard"
+		case strings.HasPrefix(segment, "*"):
+			name = segment[1:]
+		default:
+			continue
+		}
+		segments[i] = "{" + name + "}"
+		parameters = append(parameters, name)
+	}
+
+	return strings.Join(segments, "/"), parameters
+}
 
 // SetPathItem register path item with some information and input, output
 func (g *Generator) SetPathItem(info PathItemInfo, params interface{}, body interface{}, response interface{}) error {
+	_, _, err := g.setPathItem(info, params, body, response)
+	return err
+}
+
+// setPathItem registers path item and returns its operation, operation that is already registered
+// for path and method is returned as is with added false
+func (g *Generator) setPathItem(info PathItemInfo, params interface{}, body interface{}, response interface{}) (op *OperationObj, added bool, err error) {
 	var (
 		item  PathItem
 		found bool
 	)
 
-	pathParametersSubmatches := regexFindPathParameter.FindAllStringSubmatch(info.Path, -1)
-	if len(pathParametersSubmatches) > 0 {
-		for _, submatch := range pathParametersSubmatches {
-			if submatch[2] != "" { // Remove gorilla.Mux-style regexp in path
-				info.Path = strings.Replace(info.Path, submatch[0], "{"+submatch[1]+"}", 1)
-			}
-		}
-	}
+	var pathParameters []string
+	info.Path, pathParameters = normalizePath(info.Path)
 
 	item, found = g.paths[info.Path]
 
 	if found && item.HasMethod(info.Method) {
-		return nil
+		return item.operation(info.Method), false, nil
 	}
 
 	if !found {
@@ -734,19 +1556,20 @@ func (g *Generator) SetPathItem(info PathItemInfo, params interface{}, body inte
 	operationObj := &OperationObj{}
 	operationObj.Summary = info.Title
 	operationObj.Description = info.Description
+	operationObj.OperationID = info.OperationID
 	operationObj.Deprecated = info.Deprecated
 	operationObj.additionalData = info.additionalData
 	if info.Tag != "" {
 		operationObj.Tags = []string{info.Tag}
 	}
 
-	operationObj.Security = make(map[string][]string)
+	security := make(map[string][]string)
 	if len(info.Security) > 0 {
 		for _, sec := range info.Security {
 			if _, ok := g.doc.SecurityDefinitions[sec]; ok {
-				operationObj.Security[sec] = []string{}
+				security[sec] = []string{}
 			} else {
-				return errors.New("Undefined security definition: " + sec)
+				return nil, false, errors.New("Undefined security definition: " + sec)
 			}
 		}
 	}
@@ -754,36 +1577,113 @@ func (g *Generator) SetPathItem(info PathItemInfo, params interface{}, body inte
 	if len(info.SecurityOAuth2) > 0 {
 		for sec, scopes := range info.SecurityOAuth2 {
 			if _, ok := g.doc.SecurityDefinitions[sec]; ok {
-				operationObj.Security[sec] = scopes
+				security[sec] = scopes
 			} else {
-				return errors.New("Undefined security definition: " + sec)
+				return nil, false, errors.New("Undefined security definition: " + sec)
+			}
+		}
+	}
+
+	if len(security) > 0 {
+		operationObj.Security = append(operationObj.Security, security)
+	}
+
+	for _, requirement := range info.SecurityRequirements {
+		for sec := range requirement {
+			if _, ok := g.doc.SecurityDefinitions[sec]; !ok {
+				return nil, false, errors.New("Undefined security definition: " + sec)
 			}
 		}
+		operationObj.Security = append(operationObj.Security, requirement)
 	}
 
 	if params != nil {
 		if g.reflectGoTypes {
-			operationObj.AddExtendedField("x-request-go-type", goType(reflect.TypeOf(params)))
+			operationObj.AddExtendedField("x-request-go-type", g.goType(reflect.TypeOf(params)))
 		}
 
 		if _, params, err := g.ParseParameter(params); err == nil {
 			operationObj.Parameters = params
 		} else {
-			return err
+			return nil, false, err
 		}
 	}
 
-	operationObj.Responses = g.parseResponseObject(response)
+	if info.ParameterSet != "" {
+		set, ok := g.paramSets[info.ParameterSet]
+		if !ok {
+			return nil, false, errors.New("Undefined parameter set: " + info.ParameterSet)
+		}
+		operationObj.Parameters = append(operationObj.Parameters, set...)
+	}
+
+	operationObj.Parameters = append(operationObj.Parameters, info.ExtraParameters...)
+
+	for _, param := range operationObj.Parameters {
+		if param.In == "path" && !Contains(pathParameters, param.Name) {
+			g.warnf("%s %s: path parameter %q is missing in route template", info.Method, info.Path, param.Name)
+		}
+	}
 
-	if body != nil {
+	for _, name := range pathParameters {
+		if !hasPathParameter(operationObj.Parameters, name) {
+			operationObj.Parameters = append(operationObj.Parameters, ParamObj{
+				Name:     name,
+				In:       "path",
+				Type:     "string",
+				Required: true,
+			})
+		}
+	}
+
+	for _, name := range info.Parameters {
+		if _, ok := g.doc.Parameters[name]; !ok {
+			return nil, false, errors.New("Undefined global parameter: " + name)
+		}
+		operationObj.Parameters = append(operationObj.Parameters, ParamObj{Ref: refParameterPrefix + name})
+	}
+
+	if response == nil && info.NoDefaultResponse {
+		operationObj.Responses = make(Responses)
+	} else if operationObj.Responses, err = g.parseResponseObject(response, info.SuccessStatus, info.SuccessDescription); err != nil {
+		return nil, false, err
+	}
+
+	for status, name := range info.Responses {
+		if _, ok := g.doc.Responses[name]; !ok {
+			return nil, false, errors.New("Undefined global response: " + name)
+		}
+		operationObj.Responses[strconv.Itoa(status)] = ResponseObj{Ref: refResponsePrefix + name}
+	}
+
+	if method := strings.ToUpper(info.Method); body != nil && !g.allowBodyForGET && (method == "GET" || method == "HEAD") {
+		g.warnf("%s %s: request body is not supported for %s method, skipped", info.Method, info.Path, method)
+		body = nil
+	}
+
+	if body != nil && info.BodyForm {
+		if g.reflectGoTypes {
+			operationObj.AddExtendedField("x-request-go-type", g.goType(reflect.TypeOf(body)))
+		}
+
+		_, params, err := g.ParseParameter(body)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, param := range params {
+			param.In = "formData"
+			operationObj.Parameters = append(operationObj.Parameters, param)
+		}
+		operationObj.Consumes = []string{"application/x-www-form-urlencoded"}
+	} else if body != nil {
 		if g.reflectGoTypes {
-			operationObj.AddExtendedField("x-request-go-type", goType(reflect.TypeOf(body)))
+			operationObj.AddExtendedField("x-request-go-type", g.goType(reflect.TypeOf(body)))
 		}
 
-		typeDef, err := g.ParseDefinition(body)
+		typeDef, err := g.parseDefinition(context.Background(), body)
 
 		if err != nil {
-			return err
+			return nil, false, err
 		}
 
 		if !typeDef.isEmpty() {
@@ -804,53 +1704,293 @@ func (g *Generator) SetPathItem(info PathItemInfo, params interface{}, body inte
 		}
 	}
 
-	switch strings.ToUpper(info.Method) {
-	case "GET":
-		item.Get = operationObj
-	case "POST":
-		item.Post = operationObj
-	case "PUT":
-		item.Put = operationObj
-	case "DELETE":
-		item.Delete = operationObj
-	case "OPTIONS":
-		item.Options = operationObj
-	case "HEAD":
-		item.Head = operationObj
-	case "PATCH":
-		item.Patch = operationObj
+	if g.autoContentType {
+		if body != nil && len(operationObj.Consumes) == 0 {
+			operationObj.Consumes = []string{"application/json"}
+		}
+		if response != nil {
+			inferProduces(operationObj)
+		}
+	}
+
+	if operationObj.Parameters, err = g.dedupeParameters(info, operationObj.Parameters); err != nil {
+		return nil, false, err
 	}
+	g.sortParameters(operationObj.Parameters)
 
+	item.setOperation(info.Method, operationObj)
 	g.paths[info.Path] = item
+	g.invalidateCache()
+
+	return operationObj, true, nil
+}
 
+// RegisterParameterSet parses parameters of i once and stores them under name to be used by operations
+// with PathItemInfo.ParameterSet
+func (g *Generator) RegisterParameterSet(name string, i interface{}) error {
+	_, params, err := g.ParseParameter(i)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	g.paramSets[name] = params
+	g.mu.Unlock()
 	return nil
 }
 
+// RegisterParameterSet parses parameters of i once and stores them under name to be used by operations
+// with PathItemInfo.ParameterSet
+func RegisterParameterSet(name string, i interface{}) error {
+	return gen.RegisterParameterSet(name, i)
+}
+
 // SetPathItem register path item with some information and input, output
 func SetPathItem(info PathItemInfo, params interface{}, body interface{}, response interface{}) error {
 	return gen.SetPathItem(info, params, body, response)
 }
 
-func (g *Generator) parseResponseObject(responseObj interface{}) (res Responses) {
+// OperationBuilder describes responses of operation registered with SetPathItemWithResponses
+type OperationBuilder struct {
+	g             *Generator
+	op            *OperationObj
+	defaultStatus string // status of generated success response, it is replaced by described responses
+}
+
+// SetPathItemWithResponses registers path item like SetPathItem and returns builder to describe its responses
+func (g *Generator) SetPathItemWithResponses(info PathItemInfo, params interface{}, body interface{}) (*OperationBuilder, error) {
+	op, added, err := g.setPathItem(info, params, body, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &OperationBuilder{g: g, op: op}
+	if added && !info.NoDefaultResponse {
+		b.defaultStatus = strconv.Itoa(info.SuccessStatus)
+		if info.SuccessStatus == 0 {
+			b.defaultStatus = strconv.Itoa(http.StatusOK)
+		}
+	}
+	return b, nil
+}
+
+// SetPathItemWithResponses registers path item like SetPathItem and returns builder to describe its responses
+func SetPathItemWithResponses(info PathItemInfo, params interface{}, body interface{}) (*OperationBuilder, error) {
+	return gen.SetPathItemWithResponses(info, params, body)
+}
+
+// Response adds response with output schema and description for HTTP status,
+// it panics if definition of output can not be parsed
+func (b *OperationBuilder) Response(status int, output interface{}, description string) *OperationBuilder {
+	if b.defaultStatus != "" {
+		delete(b.op.Responses, b.defaultStatus)
+		b.defaultStatus = ""
+	}
+
+	responses, err := b.g.parseResponseObject(output, status, description)
+	if err != nil {
+		panic(fmt.Sprintf("could not create schema object for response %v: %v", output, err))
+	}
+	for code, res := range responses {
+		b.op.Responses[code] = res
+	}
+	if output != nil && b.g.autoContentType {
+		inferProduces(b.op)
+	}
+	b.g.invalidateCache()
+	return b
+}
+
+// ResponseWithContentType adds response like Response and adds contentType to MIME types the operation produces
+func (b *OperationBuilder) ResponseWithContentType(status int, output interface{}, description, contentType string) *OperationBuilder {
+	if !Contains(b.op.Produces, contentType) {
+		b.op.Produces = append(b.op.Produces, contentType)
+	}
+	return b.Response(status, output, description)
+}
+
+// inferProduces sets application/json MIME type that op produces unless it is set explicitly
+func inferProduces(op *OperationObj) {
+	if len(op.Produces) == 0 {
+		op.Produces = []string{"application/json"}
+	}
+}
+
+// EventStream adds server-sent events response for HTTP status, the operation produces text/event-stream
+// and schema of the response describes data of a single event
+func (b *OperationBuilder) EventStream(status int, event interface{}, description string) *OperationBuilder {
+	if description == "" {
+		description = "stream of server-sent events, schema describes data of a single event"
+	}
+	return b.ResponseWithContentType(status, event, description, "text/event-stream")
+}
+
+// AddResponseDescription adds response without schema having only description for HTTP status, e.g. of an error,
+// it replaces generated success response only if status is a success one
+func (b *OperationBuilder) AddResponseDescription(status int, description string) *OperationBuilder {
+	if b.defaultStatus != "" && status >= 200 && status < 300 {
+		delete(b.op.Responses, b.defaultStatus)
+		b.defaultStatus = ""
+	}
+
+	if description == "" {
+		description = b.g.statusDescription(status)
+	}
+	b.op.Responses[strconv.Itoa(status)] = ResponseObj{Description: description}
+	b.g.invalidateCache()
+	return b
+}
+
+// AddResponseExample adds example of response for HTTP status keyed by its MIME type, example is marshaled to JSON,
+// it should be called after the response is described, otherwise response having only description is added,
+// it panics if example can not be marshaled
+func (b *OperationBuilder) AddResponseExample(status int, mimeType string, example interface{}) *OperationBuilder {
+	data, err := json.Marshal(example)
+	if err != nil {
+		panic(fmt.Sprintf("could not marshal example of response %d: %v", status, err))
+	}
+
+	code := strconv.Itoa(status)
+	res, found := b.op.Responses[code]
+	if !found {
+		res.Description = b.g.statusDescription(status)
+	}
+
+	examples, _ := res.Examples.(map[string]interface{})
+	if examples == nil {
+		examples = make(map[string]interface{}, 1)
+	}
+	examples[mimeType] = json.RawMessage(data)
+	res.Examples = examples
+
+	b.op.Responses[code] = res
+	b.g.invalidateCache()
+	return b
+}
+
+// dedupeParameters removes parameters with the same name and location keeping the last of them
+// at position of the first one, it fails with strict parameters
+func (g *Generator) dedupeParameters(info PathItemInfo, params []ParamObj) ([]ParamObj, error) {
+	positions := make(map[string]int, len(params))
+	result := params[:0:0]
+	for _, param := range params {
+		resolved := param
+		if param.Ref != "" {
+			resolved = g.doc.Parameters[strings.TrimPrefix(param.Ref, refParameterPrefix)]
+		}
+
+		key := resolved.In + " " + resolved.Name
+		i, found := positions[key]
+		if !found {
+			positions[key] = len(result)
+			result = append(result, param)
+			continue
+		}
+
+		if g.strictParameters {
+			return nil, fmt.Errorf("%s %s: duplicate %s parameter %q", info.Method, info.Path, resolved.In, resolved.Name)
+		}
+		g.warnf("%s %s: duplicate %s parameter %q, the last one is used", info.Method, info.Path, resolved.In, resolved.Name)
+		result[i] = param
+	}
+	return result, nil
+}
+
+// parameterLocationOrder defines canonical order of parameters by location
+var parameterLocationOrder = map[string]int{"path": 0, "query": 1, "header": 2, "formData": 3, "body": 4}
+
+// sortParameters sorts params by location in canonical order keeping order of parameters of the same location
+func (g *Generator) sortParameters(params []ParamObj) {
+	order := make([]int, len(params))
+	for i, param := range params {
+		if param.Ref != "" {
+			param = g.doc.Parameters[strings.TrimPrefix(param.Ref, refParameterPrefix)]
+		}
+		order[i] = parameterLocationOrder[param.In]
+	}
+	sort.Stable(parametersByLocation{params: params, order: order})
+}
+
+// parametersByLocation sorts parameters by their location order
+type parametersByLocation struct {
+	params []ParamObj
+	order  []int
+}
+
+func (p parametersByLocation) Len() int           { return len(p.params) }
+func (p parametersByLocation) Less(i, j int) bool { return p.order[i] < p.order[j] }
+func (p parametersByLocation) Swap(i, j int) {
+	p.params[i], p.params[j] = p.params[j], p.params[i]
+	p.order[i], p.order[j] = p.order[j], p.order[i]
+}
+
+// hasPathParameter checks if params contain a path parameter with given name
+func hasPathParameter(params []ParamObj, name string) bool {
+	for _, param := range params {
+		if param.In == "path" && param.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultStatusDescriptions are descriptions of responses added without explicit description
+var defaultStatusDescriptions = map[int]string{
+	http.StatusOK:                  "request success",
+	http.StatusCreated:             "created",
+	http.StatusAccepted:            "accepted",
+	http.StatusNoContent:           "no content",
+	http.StatusBadRequest:          "bad request",
+	http.StatusUnauthorized:        "unauthorized",
+	http.StatusForbidden:           "forbidden",
+	http.StatusNotFound:            "not found",
+	http.StatusConflict:            "conflict",
+	http.StatusInternalServerError: "internal server error",
+}
+
+// statusDescription returns description of response with HTTP status, registered descriptions
+// take precedence over defaults, lowercase status text is used for unknown statuses
+func (g *Generator) statusDescription(status int) string {
+	if description, ok := g.statusDescriptions[status]; ok {
+		return description
+	}
+	if description, ok := defaultStatusDescriptions[status]; ok {
+		return description
+	}
+	return strings.ToLower(http.StatusText(status))
+}
+
+func (g *Generator) parseResponseObject(responseObj interface{}, status int, description string) (res Responses, err error) {
 	res = make(Responses)
 
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if description == "" {
+		description = g.statusDescription(status)
+	}
+	code := strconv.Itoa(status)
+
 	if responseObj != nil {
-		schema, err := g.ParseDefinition(responseObj)
+		schema, err := g.parseDefinition(context.Background(), responseObj)
 		if err != nil {
-			panic(fmt.Sprintf("could not create schema object for response %v", responseObj))
+			return nil, err
+		}
+		if g.responseWrapper != nil {
+			schema = g.responseWrapper(schema)
 		}
 		// since we only response json object
 		// so, type of response object is always object
-		res["200"] = ResponseObj{
-			Description: "request success",
+		res[code] = ResponseObj{
+			Description: description,
 			Schema:      &schema,
 		}
 	} else {
-		res["200"] = ResponseObj{
-			Description: "request success",
+		res[code] = ResponseObj{
+			Description: description,
 			Schema:      &SchemaObj{Type: "null"},
 		}
 	}
 
-	return res
+	return res, nil
 }
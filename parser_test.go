@@ -1,11 +1,20 @@
 package swgen
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"net/http"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
 	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/lazada/swgen/sample"
 )
 
 type Person struct {
@@ -126,241 +135,2798 @@ func TestParseDefinitionWithEmbeddedInterface(t *testing.T) {
 	}
 }
 
-func TestParseDefinitionString(t *testing.T) {
-	typeDef, err := ParseDefinition("string")
-	name := typeDef.TypeName
-	if err != nil {
-		t.Fatalf("Error parsing string: %+v", err)
-	}
-	if name != "string" {
-		t.Fatalf("Wrong type name. Expect %q, got %q", "string", name)
+func TestParseDefinitionDeprecatedProperty(t *testing.T) {
+	type Account struct {
+		Login    string `json:"login"`
+		Nickname string `json:"nickname" deprecated:"true"`
 	}
-}
 
-func TestParseDefinitionArray(t *testing.T) {
-	type Names []string
-	typeDef, err := ParseDefinition(Names{})
-	if err != nil {
-		t.Fatalf("Error while parsing array of string: %v", err)
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Account{}); err != nil {
+		t.Fatalf("%v", err)
 	}
 
-	if typeDef.TypeName != "Names" {
-		t.Fatalf("Wrong type name. Expected: Names, Obtained: %v", typeDef.TypeName)
+	typeDef, found := g.getDefinition(reflect.TypeOf(Account{}))
+	if !found {
+		t.Fatal("No definition for Account")
+	}
+	if typeDef.Properties["login"].Deprecated {
+		t.Fatal("login should not be deprecated")
+	}
+	if !typeDef.Properties["nickname"].Deprecated {
+		t.Fatal("nickname should be deprecated")
 	}
 
-	// re-parse with pointer input
-	// should get from definition list
-	_, err = ParseDefinition(&Names{})
+	data, err := json.Marshal(typeDef.Properties["nickname"])
 	if err != nil {
-		t.Fatalf("Error while parsing array of string: %v", err)
+		t.Fatalf("%v", err)
+	}
+	if string(data) != `{"type":"string","x-deprecated":true}` {
+		t.Fatalf("unexpected JSON of deprecated property: %s", data)
 	}
 
-	// try to parse a named map
-	type MapList map[string]string
-	_, err = ParseDefinition(&MapList{})
-	if err != nil {
-		t.Fatalf("Error while parsing map string to string: %v", err)
+	type Filter struct {
+		Name  string `query:"name"`
+		Login string `query:"login" deprecated:"true"`
 	}
 
-	// named array of object
-	type Person struct{}
-	type Persons []*Person
-	_, err = ParseDefinition(&Persons{})
+	_, params, err := g.ParseParameter(Filter{})
 	if err != nil {
-		t.Fatalf("Error while parsing array of object: %v", err)
+		t.Fatalf("%v", err)
+	}
+	if params[0].Deprecated || !params[1].Deprecated {
+		t.Fatalf("only login parameter should be deprecated: %#v", params)
 	}
 }
 
-func TestParseParameter(t *testing.T) {
-	p := &PreferredWarehouseRequest{}
-	name, params, err := ParseParameter(p)
+type textOnlyMarshaler struct {
+	value string
+}
 
-	if err != nil {
-		t.Fatalf("error %v", err)
+func (m textOnlyMarshaler) MarshalText() ([]byte, error) {
+	return []byte(m.value), nil
+}
+
+func TestParseDefinitionTextMarshaler(t *testing.T) {
+	type Holder struct {
+		Value    textOnlyMarshaler  `json:"value"`
+		ValuePtr *textOnlyMarshaler `json:"value_ptr"`
 	}
 
-	if name != "PreferredWarehouseRequest" {
-		t.Fatalf("name of parameter is %s, expected is PreferredWarehouseRequest", name)
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Holder{}); err != nil {
+		t.Fatalf("%v", err)
 	}
 
-	if len(params) != 2 {
-		t.Fatalf("number of parameter should be 2")
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Holder{}))
+	for _, name := range []string{"value", "value_ptr"} {
+		if prop := typeDef.Properties[name]; prop.Type != "string" || prop.Ref != "" {
+			t.Fatalf("%s should be a string, got %#v", name, prop)
+		}
 	}
-}
 
-func TestParseParameterError(t *testing.T) {
-	_, _, err := ParseParameter(true)
-	if err == nil {
-		t.Fatalf("it should return error")
+	if _, found := g.getDefinition(reflect.TypeOf(textOnlyMarshaler{})); found {
+		t.Fatal("unexpected definition for text marshaler")
 	}
 }
 
-//
-// test and data for TestSetPathItem
-//
-
-func TestSetPathItem(t *testing.T) {
-	h := &testHandler{}
+func TestParseDefinitionDateFormat(t *testing.T) {
+	type Employee struct {
+		HiredAt   time.Time  `json:"hired_at"`
+		BirthDate time.Time  `json:"birth_date" swgen_type:"date"`
+		FiredOn   *time.Time `json:"fired_on" swgen_format:"date"`
+	}
 
-	methods := []string{"GET", "POST", "HEAD", "PUT", "OPTIONS", "DELETE", "PATCH"}
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Employee{}); err != nil {
+		t.Fatalf("%v", err)
+	}
 
-	for _, method := range methods {
-		info := PathItemInfo{
-			Path:        "/v1/test/handler",
-			Title:       "TestHandler",
-			Description: fmt.Sprintf("This is just a test handler with %s request", method),
-			Method:      method,
-		}
-		err := SetPathItem(info, h.GetRequestBuffer(method), h.GetBodyBuffer(), h.GetResponseBuffer(method))
-		if err != nil {
-			t.Fatalf("error %v", err)
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Employee{}))
+	expected := map[string]string{"hired_at": "date-time", "birth_date": "date", "fired_on": "date"}
+	for name, format := range expected {
+		if prop := typeDef.Properties[name]; prop.Type != "string" || prop.Format != format {
+			t.Fatalf("%s should be a string of %s format, got %#v", name, format, prop)
 		}
 	}
 }
 
-func TestResetPaths(t *testing.T) {
-	TestSetPathItem(t)
+func TestParseDefinitionDecimal(t *testing.T) {
+	type Payment struct {
+		Amount   string  `json:"amount" query:"amount" swgen_type:"decimal" pattern:"^-?[0-9]+(\\.[0-9]{1,2})?$"`
+		Fee      float64 `json:"fee" query:"fee" swgen_type:"decimal"`
+		Currency string  `json:"currency" query:"currency"`
+	}
 
-	if len(gen.paths) == 0 {
-		t.Fatalf("len of gen.paths must be greater than 0")
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Payment{}); err != nil {
+		t.Fatalf("%v", err)
 	}
 
-	ResetPaths()
-	if len(gen.paths) != 0 {
-		t.Fatalf("len of gen.paths must be equal to 0")
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Payment{}))
+	for _, name := range []string{"amount", "fee"} {
+		if prop := typeDef.Properties[name]; prop.Type != "string" || prop.Format != "decimal" {
+			t.Fatalf("%s should be a decimal string, got %#v", name, prop)
+		}
+	}
+	if pattern := typeDef.Properties["amount"].Pattern; pattern != `^-?[0-9]+(\.[0-9]{1,2})?$` {
+		t.Fatalf("unexpected pattern %q", pattern)
+	}
+	if prop := typeDef.Properties["currency"]; prop.Format != "" || prop.Pattern != "" {
+		t.Fatalf("unexpected currency %#v", prop)
 	}
-}
 
-//
-// benchmark and parallel testing
-//
+	_, params, err := g.ParseParameter(Payment{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if amount := params[0]; amount.Type != "string" || amount.Format != "decimal" || amount.Pattern != `^-?[0-9]+(\.[0-9]{1,2})?$` {
+		t.Fatalf("unexpected parameter %#v", amount)
+	}
+}
 
-func BenchmarkParseDefinitionsParallel(b *testing.B) {
-	var (
-		mu sync.Mutex
-		i  int
-	)
+type Date time.Time
 
-	data := []interface{}{
-		&Person{},
-		&PersonName{},
-		&PreferredWarehouseRequest{},
+func TestParseDefinitionTimeTypes(t *testing.T) {
+	type Stamped struct {
+		At time.Time `json:"at"`
+	}
+	type Holiday struct {
+		Day      Date    `json:"day" query:"day"`
+		Observed *Date   `json:"observed"`
+		Moved    []Date  `json:"moved"`
+		Stamp    Stamped `json:"stamp"`
 	}
 
-	b.SetParallelism(10)
-	b.RunParallel(func(pb *testing.PB) {
-		for pb.Next() {
-			mu.Lock()
-			i++
-			input := data[i%3]
-			mu.Unlock()
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Holiday{}); err != nil {
+		t.Fatalf("%v", err)
+	}
 
-			if _, err := ParseDefinition(input); err != nil {
-				b.Fatalf("%v", err)
-			}
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Holiday{}))
+	for _, prop := range []SchemaObj{typeDef.Properties["day"], typeDef.Properties["observed"], *typeDef.Properties["moved"].Items} {
+		if prop.Type != "string" || prop.Format != "date-time" || prop.Ref != "" {
+			t.Fatalf("date-time string expected, got %#v", prop)
 		}
-	})
+	}
+	if ref := typeDef.Properties["stamp"].Ref; ref != "#/definitions/Stamped" {
+		t.Fatalf("struct with time field should be referenced, got %q", ref)
+	}
+
+	_, params, err := g.ParseParameter(Holiday{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(params) != 1 || params[0].Type != "string" || params[0].Format != "date-time" {
+		t.Fatalf("unexpected parameters %#v", params)
+	}
 }
 
-func BenchmarkSetPathItem(b *testing.B) {
-	h := &testHandler{}
+func TestRegisterCommonName(t *testing.T) {
+	type Invoice struct {
+		Total    float64 `json:"total" query:"total" swgen_type:"money"`
+		Password string  `json:"password" swgen_type:"password"`
+	}
 
-	infos := []PathItemInfo{
-		{
-			Path:        "/v1/test/handler",
-			Title:       "TestHandler",
-			Description: "This is just a test handler with GET request",
-			Method:      "GET",
-		},
-		{
-			Path:        "/v1/test/handler",
-			Title:       "TestHandler",
-			Description: "This is just a test handler with POST reqest",
-			Method:      "POST",
-		},
+	g := NewGenerator()
+	g.RegisterCommonName("money", SchemaObj{Type: "string", Format: "decimal", Pattern: `^[0-9]+\.[0-9]{2}$`})
+	if _, err := g.ParseDefinition(Invoice{}); err != nil {
+		t.Fatalf("%v", err)
 	}
 
-	var (
-		mu sync.Mutex
-		i  int
-	)
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Invoice{}))
+	if total := typeDef.Properties["total"]; total.Type != "string" || total.Format != "decimal" || total.Pattern != `^[0-9]+\.[0-9]{2}$` {
+		t.Fatalf("unexpected schema of registered common name %#v", total)
+	}
+	if password := typeDef.Properties["password"]; password.Type != "string" || password.Format != "password" {
+		t.Fatalf("unexpected schema of built-in common name %#v", password)
+	}
 
-	b.SetParallelism(10)
-	b.RunParallel(func(pb *testing.PB) {
-		for pb.Next() {
-			mu.Lock()
-			i++
-			info := infos[i%2]
-			mu.Unlock()
+	_, params, err := g.ParseParameter(Invoice{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(params) != 1 || params[0].Type != "string" || params[0].Format != "decimal" {
+		t.Fatalf("unexpected parameters %#v", params)
+	}
+}
 
-			err := SetPathItem(
-				info,
-				h.GetRequestBuffer(info.Method),
-				h.GetBodyBuffer(),
-				h.GetResponseBuffer(info.Method),
-			)
+func TestParseDefinitionBigNumbers(t *testing.T) {
+	type Balance struct {
+		Amount *big.Int  `json:"amount"`
+		Rate   big.Float `json:"rate"`
+	}
 
-			if err != nil {
-				b.Fatalf("error %v", err)
-			}
-		}
-	})
-}
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Balance{}); err != nil {
+		t.Fatalf("%v", err)
+	}
 
-// testHandler can handle POST and GET request
-type testHandler struct{}
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Balance{}))
+	if amount := typeDef.Properties["amount"]; amount.Type != "integer" || amount.Ref != "" {
+		t.Fatalf("amount should be an integer, got %#v", amount)
+	}
+	if rate := typeDef.Properties["rate"]; rate.Type != "number" || rate.Ref != "" {
+		t.Fatalf("rate should be a number, got %#v", rate)
+	}
 
-func (th *testHandler) GetName() string {
-	return "TestHandle"
+	if _, found := g.getDefinition(reflect.TypeOf(big.Int{})); found {
+		t.Fatal("unexpected definition for big.Int")
+	}
 }
 
-func (th *testHandler) GetDescription() string {
-	return "This handler for test ParsePathItem"
-}
+func TestParseDefinitionFreeFormArray(t *testing.T) {
+	type Batch struct {
+		Items []interface{} `json:"items"`
+	}
 
-func (th *testHandler) GetVersion() string {
-	return "v1"
-}
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Batch{}); err != nil {
+		t.Fatalf("%v", err)
+	}
 
-func (th *testHandler) GetRoute() string {
-	return "/test/handler"
-}
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Batch{}))
+	data, err := json.Marshal(typeDef.Properties["items"])
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if string(data) != `{"type":"array","items":{}}` {
+		t.Fatalf("array of any expected, got %s", data)
+	}
 
-func (th *testHandler) GetRequestBuffer(_ string) interface{} {
-	return &PersonName{}
+	schema, err := g.ParseDefinition([]interface{}{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if data, _ := json.Marshal(schema); string(data) != `{"type":"array","items":{}}` {
+		t.Fatalf("array of any expected, got %s", data)
+	}
 }
 
-func (th *testHandler) GetResponseBuffer(method string) interface{} {
-	if method == "GET" {
-		return nil
+func TestParseDefinitionFreeFormMap(t *testing.T) {
+	type Event struct {
+		Attributes map[string]interface{} `json:"attributes"`
 	}
 
-	return &PreferredWarehouseRequest{}
-}
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Event{}); err != nil {
+		t.Fatalf("%v", err)
+	}
 
-func (th *testHandler) GetBodyBuffer() interface{} {
-	return &Person{}
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Event{}))
+	data, err := json.Marshal(typeDef.Properties["attributes"])
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if string(data) != `{"type":"object","additionalProperties":{}}` {
+		t.Fatalf("object with any properties expected, got %s", data)
+	}
+
+	schema, err := g.ParseDefinition(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if data, _ := json.Marshal(schema); string(data) != `{"type":"object","additionalProperties":{}}` {
+		t.Fatalf("object with any properties expected, got %s", data)
+	}
 }
 
-func (th *testHandler) HandlePost(_ interface{}, _ interface{}) (response interface{}, err error) {
-	// yes, I can handle a POST request
-	return
+func TestParseDefinitionTagWithoutName(t *testing.T) {
+	type Counter struct {
+		Title string `json:",omitempty"`
+		Count int64  `json:",string"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Counter{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Counter{}))
+	if _, found := typeDef.Properties[""]; found {
+		t.Fatalf("unexpected property with empty name %#v", typeDef.Properties)
+	}
+	for _, name := range []string{"Title", "Count"} {
+		if _, found := typeDef.Properties[name]; !found {
+			t.Fatalf("property %s not found in %#v", name, typeDef.Properties)
+		}
+	}
 }
 
-func (th *testHandler) HandleGet(_ interface{}) (response interface{}, err error) {
-	// yes, I can handle a GET request
-	return
+func TestParseDefinitionBytes(t *testing.T) {
+	type Attachment struct {
+		Checksum []byte `json:"checksum"`
+		Content  []byte `json:"content" swgen_format:"binary"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Attachment{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Attachment{}))
+	expected := map[string]string{"checksum": "byte", "content": "binary"}
+	for name, format := range expected {
+		if prop := typeDef.Properties[name]; prop.Type != "string" || prop.Format != format || prop.Items != nil {
+			t.Fatalf("%s should be a string of %s format, got %#v", name, format, prop)
+		}
+	}
 }
 
-//
-// Test helper
-//
+func TestParseDefinitionSliceOfPointers(t *testing.T) {
+	type Shelter struct {
+		Pets    []testPet  `json:"pets"`
+		PetPtrs []*testPet `json:"pet_ptrs"`
+	}
 
-func getReadableJSON(i interface{}, t *testing.T) []byte {
-	data, err := json.MarshalIndent(i, "", "  ")
+	g := NewGenerator()
+	values, err := g.ParseDefinition([]testPet{})
 	if err != nil {
-		t.Fatalf("error while parsing struct to JSON string: %v", err)
+		t.Fatalf("%v", err)
+	}
+	pointers, err := g.ParseDefinition([]*testPet{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !reflect.DeepEqual(values, pointers) {
+		t.Fatalf("[]*testPet schema %#v differs from []testPet schema %#v", pointers, values)
+	}
+	if values.Type != "array" || values.Items.Ref != "#/definitions/testPet" {
+		t.Fatalf("unexpected schema %#v", values)
+	}
+
+	values, err = g.ParseDefinition([]struct {
+		Name string `json:"name"`
+	}{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	pointers, err = g.ParseDefinition([]*struct {
+		Name string `json:"name"`
+	}{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !reflect.DeepEqual(values, pointers) {
+		t.Fatalf("slice of anonymous struct pointers schema %#v differs from slice of anonymous structs schema %#v",
+			pointers, values)
+	}
+	if _, found := values.Items.Properties["name"]; !found {
+		t.Fatalf("unexpected item schema %#v", values.Items)
+	}
+
+	if _, err := g.ParseDefinition(Shelter{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	shelter, _ := g.getDefinition(reflect.TypeOf(Shelter{}))
+	if !reflect.DeepEqual(shelter.Properties["pets"], shelter.Properties["pet_ptrs"]) {
+		t.Fatalf("pet_ptrs property %#v differs from pets property %#v",
+			shelter.Properties["pet_ptrs"], shelter.Properties["pets"])
+	}
+}
+
+func TestParseDefinitionExtensions(t *testing.T) {
+	type Profile struct {
+		Nickname string `json:"nickname" swgen_ext:"x-order=3,x-nullable=true,x-widget=text"`
+		Email    string `json:"email"`
+		Tags     string `json:"tags" swgen_ext:"x-tags=[\"a\",\"b\"],x-meta={\"k\":\"v,w\"},x-order=1"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Profile{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Profile{}))
+	data, err := json.Marshal(typeDef)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var def struct {
+		Properties map[string]map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &def); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	expected := map[string]interface{}{"type": "string", "x-order": float64(3), "x-nullable": true, "x-widget": "text"}
+	if !reflect.DeepEqual(def.Properties["nickname"], expected) {
+		t.Fatalf("unexpected nickname property %v", def.Properties["nickname"])
+	}
+	if !reflect.DeepEqual(def.Properties["email"], map[string]interface{}{"type": "string"}) {
+		t.Fatalf("unexpected email property %v", def.Properties["email"])
+	}
+
+	expected = map[string]interface{}{"type": "string", "x-tags": []interface{}{"a", "b"},
+		"x-meta": map[string]interface{}{"k": "v,w"}, "x-order": float64(1)}
+	if !reflect.DeepEqual(def.Properties["tags"], expected) {
+		t.Fatalf("unexpected tags property %v", def.Properties["tags"])
+	}
+}
+
+func TestPasswordFormat(t *testing.T) {
+	type Login struct {
+		Username string `json:"username" query:"username"`
+		Password string `json:"password" query:"password" swgen_type:"password"`
+		Secret   string `json:"secret" query:"secret" swgen_format:"password"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Login{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Login{}))
+	expected := map[string]string{"username": "", "password": "password", "secret": "password"}
+	for name, format := range expected {
+		if prop := typeDef.Properties[name]; prop.Type != "string" || prop.Format != format {
+			t.Fatalf("unexpected %s property %#v", name, prop)
+		}
+	}
+
+	_, params, err := g.ParseParameter(Login{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	for _, param := range params {
+		if param.Type != "string" || param.Format != expected[param.Name] {
+			t.Fatalf("unexpected %s parameter %#v", param.Name, param)
+		}
+	}
+}
+
+func TestParseDefinitionEnum(t *testing.T) {
+	type Member struct {
+		Gender Gender `json:"gender"`
+		Flag   *Flag  `json:"flag"`
+		Name   string `json:"name"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Member{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Member{}))
+	data, err := json.Marshal(typeDef)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var def struct {
+		Properties map[string]struct {
+			Enum         []interface{} `json:"enum"`
+			EnumVarNames []string      `json:"x-enum-varnames"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &def); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	gender := def.Properties["gender"]
+	if !reflect.DeepEqual(gender.Enum, []interface{}{float64(0), float64(1), float64(2), float64(3)}) ||
+		!reflect.DeepEqual(gender.EnumVarNames, []string{"PreferNotToDisclose", "Male", "Female", "LGBT"}) {
+		t.Fatalf("unexpected gender enum %v %v", gender.Enum, gender.EnumVarNames)
+	}
+
+	flag := def.Properties["flag"]
+	if !reflect.DeepEqual(flag.Enum, []interface{}{"Foo", "Bar"}) ||
+		!reflect.DeepEqual(flag.EnumVarNames, []string{"Foo", "Bar"}) {
+		t.Fatalf("unexpected flag enum %v %v", flag.Enum, flag.EnumVarNames)
+	}
+
+	if name := def.Properties["name"]; name.Enum != nil || name.EnumVarNames != nil {
+		t.Fatalf("unexpected name enum %v %v", name.Enum, name.EnumVarNames)
+	}
+}
+
+// cancelingDefinition cancels parsing context once its definition is parsed
+type cancelingDefinition struct{}
+
+var cancelParsing func()
+
+func (cancelingDefinition) SwgenDefinition() (typeName string, typeDef SchemaObj, err error) {
+	cancelParsing()
+	return "cancelingDefinition", SchemaFromCommonName(CommonNameString), nil
+}
+
+func TestParseDefinitionCtx(t *testing.T) {
+	type Leaf struct {
+		Name string `json:"name"`
+	}
+	type Root struct {
+		Canceling cancelingDefinition `json:"canceling"`
+		Leaf      Leaf                `json:"leaf"`
+		Leaves    []Leaf              `json:"leaves"`
+		Person    Person              `json:"person"`
+	}
+
+	g := NewGenerator()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := g.ParseDefinitionCtx(ctx, Root{}); err != context.Canceled {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(g.definitions) != 0 {
+		t.Fatalf("unexpected definitions %v", g.definitions)
+	}
+
+	ctx, cancelParsing = context.WithCancel(context.Background())
+	if _, err := g.ParseDefinitionCtx(ctx, Root{}); err != context.Canceled {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, found := g.getDefinition(reflect.TypeOf(Root{})); !found {
+		t.Fatal("Root definition should be added")
+	}
+
+	// remaining definitions are parsed on generation
+	definitions := g.Definitions()
+	for _, name := range []string{"Root", "Leaf", "Person", "PersonName", "cancelingDefinition"} {
+		if _, found := definitions[name]; !found {
+			t.Fatalf("definition %s not found in %v", name, definitions)
+		}
+	}
+}
+
+func TestParseDefinitionAnonymousField(t *testing.T) {
+	type Order struct {
+		ID       int `json:"id"`
+		Delivery struct {
+			Address string `json:"address"`
+			Courier Person `json:"courier"`
+		} `json:"delivery"`
+		Items []struct {
+			SKU string `json:"sku"`
+		} `json:"items"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Order{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Order{}))
+	delivery := typeDef.Properties["delivery"]
+	if delivery.Ref != "" || delivery.Type != "object" || len(delivery.Properties) != 2 {
+		t.Fatalf("delivery should be inline object, got %#v", delivery)
+	}
+	if courier := delivery.Properties["courier"]; courier.Ref != "#/definitions/Person" {
+		t.Fatalf("unexpected courier property %#v", courier)
+	}
+
+	items := typeDef.Properties["items"]
+	if items.Items == nil || items.Items.Ref != "" || items.Items.Properties["sku"].Type != "string" {
+		t.Fatalf("items should be array of inline objects, got %#v", items)
+	}
+
+	for name := range g.Definitions() {
+		if name != "Order" && name != "Person" && name != "PersonName" {
+			t.Fatalf("unexpected definition %s", name)
+		}
+	}
+}
+
+func TestParseDefinitionEmptyInterfaceProperty(t *testing.T) {
+	type Event struct {
+		Name    string      `json:"name"`
+		Payload interface{} `json:"payload"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Event{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Event{}))
+	data, err := json.Marshal(typeDef.Properties["payload"])
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if string(data) != "{}" {
+		t.Fatalf("payload should be described with empty schema, got %s", data)
+	}
+}
+
+func TestParseDefinitionMapOfPointers(t *testing.T) {
+	type Directory struct {
+		People    map[string]Person  `json:"people"`
+		PeoplePtr map[string]*Person `json:"people_ptr"`
+	}
+
+	g := NewGenerator()
+	values, err := g.ParseDefinition(map[string]Person{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	pointers, err := g.ParseDefinition(map[string]*Person{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !reflect.DeepEqual(values, pointers) {
+		t.Fatalf("map[string]*Person schema %#v differs from map[string]Person schema %#v", pointers, values)
+	}
+	if values.AdditionalProperties == nil || values.AdditionalProperties.Ref != "#/definitions/Person" {
+		t.Fatalf("unexpected schema %#v", values)
+	}
+
+	if _, err := g.ParseDefinition(Directory{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	directory, _ := g.getDefinition(reflect.TypeOf(Directory{}))
+	if !reflect.DeepEqual(directory.Properties["people"], directory.Properties["people_ptr"]) {
+		t.Fatalf("people_ptr property %#v differs from people property %#v",
+			directory.Properties["people_ptr"], directory.Properties["people"])
+	}
+}
+
+type testUUID string
+
+type testBinaryUUID [16]byte
+
+func TestFormatHeuristics(t *testing.T) {
+	type Account struct {
+		ID           testUUID       `json:"id" query:"id"`
+		BinaryID     testBinaryUUID `json:"binary_id" query:"binary_id"`
+		Email        string         `json:"email" query:"email"`
+		BackupEmail  *string        `json:"backup_email" query:"backup_email"`
+		AvatarURL    string         `json:"avatar_url" query:"avatar_url"`
+		HomepageURL  string         `json:"homepage_url" query:"homepage_url" swgen_format:"url"`
+		ContactEmail string         `json:"contact_email" query:"contact_email" swgen_type:"string"`
+		Emails       int            `json:"emails" query:"emails"`
+	}
+
+	parse := func(g *Generator) map[string]string {
+		formats := make(map[string]string)
+		if _, err := g.ParseDefinition(Account{}); err != nil {
+			t.Fatalf("%v", err)
+		}
+		typeDef, _ := g.getDefinition(reflect.TypeOf(Account{}))
+		for name, prop := range typeDef.Properties {
+			formats[name] = prop.Format
+		}
+
+		_, params, err := g.ParseParameter(Account{})
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		for _, param := range params {
+			if param.Format != formats[param.Name] {
+				t.Fatalf("format of %s parameter %q differs from property format %q", param.Name, param.Format, formats[param.Name])
+			}
+		}
+		return formats
+	}
+
+	formats := parse(NewGenerator().RegisterUUIDType(testUUID(""), testBinaryUUID{}))
+	expected := map[string]string{
+		"id": "", "binary_id": "", "email": "", "backup_email": "", "avatar_url": "", "homepage_url": "url", "contact_email": "", "emails": "int32",
+	}
+	if !reflect.DeepEqual(formats, expected) {
+		t.Fatalf("unexpected formats without heuristics %v", formats)
+	}
+
+	formats = parse(NewGenerator().RegisterUUIDType(testUUID(""), testBinaryUUID{}).SetFormatHeuristics(true))
+	expected = map[string]string{
+		"id": "uuid", "binary_id": "uuid", "email": "email", "backup_email": "email", "avatar_url": "uri", "homepage_url": "url", "contact_email": "", "emails": "int32",
+	}
+	if !reflect.DeepEqual(formats, expected) {
+		t.Fatalf("unexpected formats with heuristics %v", formats)
+	}
+
+	g := NewGenerator().RegisterUUIDType(testBinaryUUID{}).SetFormatHeuristics(true)
+	if _, err := g.ParseDefinition(Account{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Account{}))
+	if prop := typeDef.Properties["binary_id"]; prop.Type != "string" || prop.Items != nil {
+		t.Fatalf("unexpected binary_id property %+v", prop)
+	}
+	_, params, err := g.ParseParameter(Account{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	for _, param := range params {
+		if param.Name == "binary_id" && (param.Type != "string" || param.Items != nil) {
+			t.Fatalf("unexpected binary_id parameter %+v", param)
+		}
+	}
+}
+
+func TestParseDefinitionArrayConstraints(t *testing.T) {
+	type Article struct {
+		Tags  []string `json:"tags" minItems:"1" maxItems:"10" uniqueItems:"true"`
+		Title string   `json:"title" minItems:"1"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Article{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Article{}))
+	data, err := json.Marshal(typeDef.Properties["tags"])
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if string(data) != `{"type":"array","items":{"type":"string"},"minItems":1,"maxItems":10,"uniqueItems":true}` {
+		t.Fatalf("unexpected JSON of tags property: %s", data)
+	}
+
+	if title := typeDef.Properties["title"]; title.MinItems != nil {
+		t.Fatalf("minItems should be ignored for non-array property: %#v", title)
+	}
+
+	type ArticleFilter struct {
+		Tags []string `query:"tags" minItems:"1" maxItems:"3"`
+	}
+
+	_, params, err := g.ParseParameter(ArticleFilter{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if params[0].MinItems == nil || *params[0].MinItems != 1 || params[0].MaxItems == nil || *params[0].MaxItems != 3 {
+		t.Fatalf("unexpected array constraints of parameter: %#v", params[0])
+	}
+	if params[0].UniqueItems {
+		t.Fatal("uniqueItems should not be set")
+	}
+}
+
+func TestParseDefinitionMultipleOf(t *testing.T) {
+	type Order struct {
+		Amount   float64 `json:"amount" multipleOf:"0.01"`
+		Quantity int     `json:"quantity" multipleOf:"5"`
+		Discount float64 `json:"discount" multipleOf:"abc"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Order{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Order{}))
+	data, err := json.Marshal(typeDef.Properties["amount"])
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if string(data) != `{"type":"number","format":"double","multipleOf":0.01}` {
+		t.Fatalf("unexpected JSON of amount property: %s", data)
+	}
+
+	if q := typeDef.Properties["quantity"].MultipleOf; q == nil || *q != 5 {
+		t.Fatalf("unexpected multipleOf of quantity: %v", q)
+	}
+	if d := typeDef.Properties["discount"].MultipleOf; d != nil {
+		t.Fatalf("invalid multipleOf should be ignored, got %v", *d)
+	}
+
+	type OrderFilter struct {
+		Quantity int `query:"quantity" multipleOf:"5"`
+	}
+
+	_, params, err := g.ParseParameter(OrderFilter{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if params[0].MultipleOf == nil || *params[0].MultipleOf != 5 {
+		t.Fatalf("unexpected multipleOf of parameter: %#v", params[0])
+	}
+}
+
+func TestParseDefinitionExclusiveBounds(t *testing.T) {
+	type Measure struct {
+		Weight float64 `json:"weight" minimum:"0" exclusiveMinimum:"true" maximum:"100"`
+		Height float64 `json:"height" exclusiveMinimum:"true" exclusiveMaximum:"true"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Measure{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Measure{}))
+	data, err := json.Marshal(typeDef.Properties["weight"])
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if string(data) != `{"type":"number","format":"double","minimum":0,"exclusiveMinimum":true,"maximum":100}` {
+		t.Fatalf("unexpected JSON of weight property: %s", data)
+	}
+
+	if height := typeDef.Properties["height"]; height.ExclusiveMinimum || height.ExclusiveMaximum {
+		t.Fatalf("exclusive flags without bounds should be ignored: %#v", height)
+	}
+}
+
+func TestParseParameterBounds(t *testing.T) {
+	type ListRequest struct {
+		Limit  int     `query:"limit" minimum:"1" maximum:"100"`
+		Offset int     `query:"offset" minimum:"0"`
+		Score  float64 `query:"score" minimum:"0" exclusiveMinimum:"true" maximum:"1" exclusiveMaximum:"true"`
+		Cursor string  `query:"cursor" minimum:"1"`
+	}
+
+	g := NewGenerator()
+	_, params, err := g.ParseParameter(ListRequest{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	expected := `[{"name":"limit","in":"query","type":"integer","format":"int32","minimum":1,"maximum":100},` +
+		`{"name":"offset","in":"query","type":"integer","format":"int32","minimum":0},` +
+		`{"name":"score","in":"query","type":"number","format":"double","minimum":0,"exclusiveMinimum":true,"maximum":1,"exclusiveMaximum":true},` +
+		`{"name":"cursor","in":"query","type":"string"}]`
+	if string(data) != expected {
+		t.Fatalf("unexpected parameters %s", data)
+	}
+}
+
+func TestParseDefinitionString(t *testing.T) {
+	typeDef, err := ParseDefinition("string")
+	name := typeDef.TypeName
+	if err != nil {
+		t.Fatalf("Error parsing string: %+v", err)
+	}
+	if name != "string" {
+		t.Fatalf("Wrong type name. Expect %q, got %q", "string", name)
+	}
+}
+
+func TestParseDefinitionArray(t *testing.T) {
+	type Names []string
+	typeDef, err := ParseDefinition(Names{})
+	if err != nil {
+		t.Fatalf("Error while parsing array of string: %v", err)
+	}
+
+	if typeDef.TypeName != "Names" {
+		t.Fatalf("Wrong type name. Expected: Names, Obtained: %v", typeDef.TypeName)
+	}
+
+	// re-parse with pointer input
+	// should get from definition list
+	_, err = ParseDefinition(&Names{})
+	if err != nil {
+		t.Fatalf("Error while parsing array of string: %v", err)
+	}
+
+	// try to parse a named map
+	type MapList map[string]string
+	_, err = ParseDefinition(&MapList{})
+	if err != nil {
+		t.Fatalf("Error while parsing map string to string: %v", err)
+	}
+
+	// named array of object
+	type Person struct{}
+	type Persons []*Person
+	_, err = ParseDefinition(&Persons{})
+	if err != nil {
+		t.Fatalf("Error while parsing array of object: %v", err)
+	}
+}
+
+func TestParseParameter(t *testing.T) {
+	p := &PreferredWarehouseRequest{}
+	name, params, err := ParseParameter(p)
+
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	if name != "PreferredWarehouseRequest" {
+		t.Fatalf("name of parameter is %s, expected is PreferredWarehouseRequest", name)
+	}
+
+	if len(params) != 2 {
+		t.Fatalf("number of parameter should be 2")
+	}
+}
+
+type PageParams struct {
+	Page  int `query:"page"`
+	Limit int `query:"limit"`
+}
+
+type SearchRequest struct {
+	PageParams `schema:"page"`
+	Query      string     `query:"q"`
+	Owner      PersonName `json:"owner"`
+}
+
+func TestParseParameterEmbeddedStruct(t *testing.T) {
+	_, params, err := NewGenerator().ParseParameter(&SearchRequest{})
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	names := make([]string, 0, len(params))
+	for _, param := range params {
+		names = append(names, param.Name)
+	}
+
+	expected := []string{"page", "limit", "q"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("unexpected parameters %v, expected %v", names, expected)
+	}
+}
+
+func TestParseParameterEmbeddedPrefix(t *testing.T) {
+	type ListRequest struct {
+		PageParams `prefix:"page_"`
+		Sort       string `query:"sort"`
+	}
+
+	_, params, err := NewGenerator().ParseParameter(ListRequest{})
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	names := make([]string, 0, len(params))
+	for _, param := range params {
+		names = append(names, param.Name)
+	}
+
+	expected := []string{"page_page", "page_limit", "sort"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("unexpected parameters %v, expected %v", names, expected)
+	}
+}
+
+func TestParseParameterDefault(t *testing.T) {
+	type ListRequest struct {
+		Limit  int      `query:"limit" default:"20"`
+		Order  string   `query:"order" default:"asc"`
+		Fields []string `query:"fields" default:"[\"id\",\"name\"]"`
+		Offset int      `query:"offset"`
+	}
+
+	g := NewGenerator()
+	_, params, err := g.ParseParameter(ListRequest{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	expected := []interface{}{int64(20), "asc", []string{"id", "name"}, nil}
+	for i, param := range params {
+		if !reflect.DeepEqual(param.Default, expected[i]) {
+			t.Fatalf("unexpected default of %s: %#v", param.Name, param.Default)
+		}
+	}
+
+	data, err := json.Marshal(params[0])
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !strings.Contains(string(data), `"default":20`) {
+		t.Fatalf("default is not marshaled: %s", data)
+	}
+}
+
+type Filters struct {
+	Status string `query:"status"`
+	Limit  int    `query:"limit"`
+}
+
+type FilteredRequest struct {
+	*Filters
+	Query string `query:"q"`
+}
+
+func TestForEachFieldEmbeddedPointer(t *testing.T) {
+	collect := func(o interface{}) map[string]interface{} {
+		values := make(map[string]interface{})
+		ForEachField(o, func(field reflect.StructField, value interface{}) bool {
+			values[field.Name] = value
+			return true
+		})
+		return values
+	}
+
+	values := collect(FilteredRequest{Filters: &Filters{Status: "active", Limit: 5}, Query: "john"})
+	expected := map[string]interface{}{"Status": "active", "Limit": 5, "Query": "john"}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("unexpected fields %v", values)
+	}
+
+	values = collect(&FilteredRequest{Query: "john"})
+	if !reflect.DeepEqual(values, map[string]interface{}{"Query": "john"}) {
+		t.Fatalf("nil embedded pointer should be skipped, got %v", values)
+	}
+}
+
+func TestParseParameterRequiredTag(t *testing.T) {
+	type UpdateRequest struct {
+		Name    string `query:"name" binding:"required"`
+		Email   string `query:"email" binding:"required" required:"false"`
+		Phone   string `query:"phone" required:"true"`
+		Address string `query:"address"`
+		ID      int    `path:"id" required:"false"`
+	}
+
+	_, params, err := NewGenerator().ParseParameter(UpdateRequest{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	expected := map[string]bool{"name": true, "email": false, "phone": true, "address": false, "id": true}
+	for _, param := range params {
+		if param.Required != expected[param.Name] {
+			t.Fatalf("unexpected required of %s: %v", param.Name, param.Required)
+		}
+	}
+}
+
+func TestSetParamDescriptions(t *testing.T) {
+	type ListRequest struct {
+		Page  int    `query:"page"`
+		Limit int    `query:"limit" description:"maximum number of people"`
+		Order string `query:"order" description:"-"`
+	}
+
+	g := NewGenerator().SetParamDescriptions(map[string]string{
+		"Page":  "page number",
+		"Limit": "page size",
+		"Order": "sort order",
+	})
+
+	_, params, err := g.ParseParameter(ListRequest{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	expected := map[string]string{"page": "page number", "limit": "maximum number of people", "order": ""}
+	for _, param := range params {
+		if param.Description != expected[param.Name] {
+			t.Fatalf("unexpected description of %s: %q", param.Name, param.Description)
+		}
+	}
+}
+
+func TestParseParameterNestedArray(t *testing.T) {
+	type MatrixRequest struct {
+		Matrix [][]int  `query:"matrix"`
+		People []Person `query:"people"`
+		Limit  int      `query:"limit"`
+	}
+
+	g := NewGenerator()
+	_, params, err := g.ParseParameter(MatrixRequest{})
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	if len(params) != 1 || params[0].Name != "limit" {
+		t.Fatalf("unsupported parameters should be skipped: %#v", params)
+	}
+
+	warnings := g.Warnings()
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "MatrixRequest.Matrix") || !strings.Contains(warnings[1], "MatrixRequest.People") {
+		t.Fatalf("unexpected warnings %v", warnings)
+	}
+}
+
+func TestParseParameterCollectionFormat(t *testing.T) {
+	type ListRequest struct {
+		IDs   []int    `schema:"ids"`
+		Tags  []string `schema:"tags" in:"formData"`
+		Langs []string `schema:"langs" in:"header"`
+	}
+
+	_, params, err := NewGenerator().ParseParameter(ListRequest{})
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if params[0].CollectionFormat != "multi" || params[1].CollectionFormat != "multi" {
+		t.Fatalf("query and formData arrays should use multi collection format %#v", params[:2])
+	}
+	if params[2].In != "header" || params[2].CollectionFormat != "csv" {
+		t.Fatalf("header array should use csv collection format %#v", params[2])
+	}
+}
+
+func TestParseParameterIn(t *testing.T) {
+	type ValidRequest struct {
+		Token string `schema:"token" in:"header"`
+		ID    int    `path:"id"`
+	}
+
+	g := NewGenerator()
+	_, params, err := g.ParseParameter(ValidRequest{})
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if params[0].In != "header" || params[0].Required {
+		t.Fatalf("unexpected header parameter %#v", params[0])
+	}
+	if params[1].In != "path" || !params[1].Required {
+		t.Fatalf("path parameter should be required %#v", params[1])
+	}
+
+	type InvalidRequest struct {
+		Token string `schema:"token" in:"queyr"`
+	}
+
+	if _, _, err := g.ParseParameter(InvalidRequest{}); err == nil || !strings.Contains(err.Error(), "queyr") {
+		t.Fatalf("error expected for invalid in tag, got %v", err)
+	}
+}
+
+func TestParseParameterError(t *testing.T) {
+	_, _, err := ParseParameter(true)
+	if err == nil {
+		t.Fatalf("it should return error")
+	}
+}
+
+//
+// test and data for TestSetPathItem
+//
+
+func TestSetPathItem(t *testing.T) {
+	h := &testHandler{}
+
+	methods := []string{"GET", "POST", "HEAD", "PUT", "OPTIONS", "DELETE", "PATCH"}
+
+	for _, method := range methods {
+		info := PathItemInfo{
+			Path:        "/v1/test/handler",
+			Title:       "TestHandler",
+			Description: fmt.Sprintf("This is just a test handler with %s request", method),
+			Method:      method,
+		}
+		err := SetPathItem(info, h.GetRequestBuffer(method), h.GetBodyBuffer(), h.GetResponseBuffer(method))
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+	}
+}
+
+func TestSetPathItemSuccessStatus(t *testing.T) {
+	g := NewGenerator()
+
+	info := PathItemInfo{
+		Path:               "/v1/people",
+		Method:             "POST",
+		Title:              "CreatePerson",
+		SuccessStatus:      http.StatusCreated,
+		SuccessDescription: "created",
+	}
+	if err := g.SetPathItem(info, nil, &Person{}, &Person{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	responses := g.paths["/v1/people"].Post.Responses
+	if _, found := responses["200"]; found {
+		t.Fatal("unexpected default 200 response")
+	}
+
+	resp, found := responses["201"]
+	if !found {
+		t.Fatalf("missing 201 response: %v", responses)
+	}
+	if resp.Description != "created" {
+		t.Fatalf("wrong description of 201 response: %q", resp.Description)
+	}
+	if resp.Schema == nil || resp.Schema.Ref != "#/definitions/Person" {
+		t.Fatalf("wrong schema of 201 response: %#v", resp.Schema)
+	}
+}
+
+func TestSetPathItemSecurityRequirements(t *testing.T) {
+	g := NewGenerator()
+	g.AddSecurityDefinition("BasicAuth", SecurityDef{Type: SecurityBasicAuth})
+	g.AddSecurityDefinition("APIKey", SecurityDef{Type: SecurityAPIKey, In: APIKeyInHeader, Name: "X-API-Key"})
+	g.AddSecurityDefinition("OAuth2", SecurityDef{Type: SecurityOAuth2, Flow: Oauth2Implicit})
+
+	info := PathItemInfo{
+		Path:   "/v1/people",
+		Method: "GET",
+		Title:  "ListPeople",
+		SecurityRequirements: []map[string][]string{
+			{"BasicAuth": {}, "APIKey": {}},
+			{"OAuth2": {"read"}},
+		},
+	}
+	if err := g.SetPathItem(info, nil, nil, []Person{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	data, err := json.Marshal(g.paths["/v1/people"].Get.Security)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if string(data) != `[{"APIKey":[],"BasicAuth":[]},{"OAuth2":["read"]}]` {
+		t.Fatalf("unexpected security %s", data)
+	}
+
+	info.Path = "/v2/people"
+	info.SecurityRequirements = []map[string][]string{{"Digest": {}}}
+	if err := g.SetPathItem(info, nil, nil, []Person{}); err == nil {
+		t.Fatal("error expected for undefined security definition")
+	}
+}
+
+func TestSetPathItemPathParameters(t *testing.T) {
+	type PersonRequest struct {
+		ID      int    `path:"id"`
+		Version string `path:"version"`
+	}
+
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/people/{id:[0-9]+}", Method: "GET", Title: "GetPerson"}
+	if err := g.SetPathItem(info, PersonRequest{}, nil, Person{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	params := g.paths["/v1/people/{id}"].Get.Parameters
+	if params[0].Name != "id" || !params[0].Required {
+		t.Fatalf("path parameter should be required %#v", params[0])
+	}
+
+	warnings := g.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"version"`) {
+		t.Fatalf("unexpected warnings %v", warnings)
+	}
+}
+
+func TestSetPathItemInferPathParameters(t *testing.T) {
+	type PersonFilter struct {
+		Fields string `query:"fields"`
+	}
+
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/people/{id}", Method: "GET", Title: "GetPerson"}
+	if err := g.SetPathItem(info, PersonFilter{}, nil, Person{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	params := g.paths["/v1/people/{id}"].Get.Parameters
+	if len(params) != 2 {
+		t.Fatalf("unexpected parameters %#v", params)
+	}
+
+	id := params[0]
+	if id.Name != "id" || id.In != "path" || id.Type != "string" || !id.Required {
+		t.Fatalf("unexpected inferred path parameter %#v", id)
+	}
+}
+
+func TestSetPathItemWithResponses(t *testing.T) {
+	type ErrorResponse struct {
+		Message string `json:"message"`
+	}
+
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/people/{id}", Method: "GET", Title: "GetPerson"}
+	b, err := g.SetPathItemWithResponses(info, nil, nil)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	b.Response(http.StatusOK, Person{}, "the person").
+		Response(http.StatusNotFound, ErrorResponse{}, "not found")
+
+	responses := g.paths["/v1/people/{id}"].Get.Responses
+	if len(responses) != 2 {
+		t.Fatalf("unexpected responses %#v", responses)
+	}
+
+	if res := responses["200"]; res.Description != "the person" || res.Schema.Ref != "#/definitions/Person" {
+		t.Fatalf("unexpected success response %#v", res)
+	}
+	if res := responses["404"]; res.Description != "not found" || res.Schema.Ref != "#/definitions/ErrorResponse" {
+		t.Fatalf("unexpected not found response %#v", res)
+	}
+}
+
+func TestAddResponseDescription(t *testing.T) {
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/people/{id}", Method: "DELETE", Title: "DeletePerson"}
+	b, err := g.SetPathItemWithResponses(info, nil, nil)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	b.AddResponseDescription(http.StatusNotFound, "").
+		AddResponseDescription(http.StatusConflict, "person has dependents")
+
+	responses := g.paths["/v1/people/{id}"].Delete.Responses
+	if len(responses) != 3 || responses["200"].Schema == nil {
+		t.Fatalf("error responses should be added next to success response %#v", responses)
+	}
+
+	data, err := json.Marshal(responses["404"])
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if string(data) != `{"description":"not found"}` {
+		t.Fatalf("unexpected not found response %s", data)
+	}
+	if res := responses["409"]; res.Description != "person has dependents" || res.Schema != nil {
+		t.Fatalf("unexpected conflict response %#v", res)
+	}
+
+	b.AddResponseDescription(http.StatusNoContent, "")
+	if _, found := responses["200"]; found || responses["204"].Description != "no content" {
+		t.Fatalf("success response should replace generated one %#v", responses)
+	}
+}
+
+func TestResponseWithContentType(t *testing.T) {
+	type Problem struct {
+		Title  string `json:"title"`
+		Status int    `json:"status"`
+	}
+
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/people/{id}", Method: "GET", Title: "GetPerson"}
+	b, err := g.SetPathItemWithResponses(info, nil, nil)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	b.ResponseWithContentType(http.StatusOK, Person{}, "", "application/json").
+		ResponseWithContentType(http.StatusNotFound, Problem{}, "", "application/problem+json").
+		ResponseWithContentType(http.StatusBadRequest, Problem{}, "", "application/problem+json")
+
+	op := g.paths["/v1/people/{id}"].Get
+	if !reflect.DeepEqual(op.Produces, []string{"application/json", "application/problem+json"}) {
+		t.Fatalf("unexpected produces %v", op.Produces)
+	}
+	if len(op.Responses) != 3 || op.Responses["404"].Schema.Ref != "#/definitions/Problem" {
+		t.Fatalf("unexpected responses %#v", op.Responses)
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	type PriceEvent struct {
+		Symbol string  `json:"symbol"`
+		Price  float64 `json:"price"`
+	}
+
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/prices", Method: "GET", Title: "StreamPrices"}
+	b, err := g.SetPathItemWithResponses(info, nil, nil)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	b.EventStream(http.StatusOK, PriceEvent{}, "")
+
+	op := g.paths["/v1/prices"].Get
+	if !reflect.DeepEqual(op.Produces, []string{"text/event-stream"}) {
+		t.Fatalf("unexpected produces %v", op.Produces)
+	}
+	response := op.Responses["200"]
+	if len(op.Responses) != 1 || response.Schema == nil || response.Schema.Ref != "#/definitions/PriceEvent" {
+		t.Fatalf("unexpected responses %#v", op.Responses)
+	}
+	if !strings.Contains(response.Description, "server-sent events") {
+		t.Fatalf("unexpected description %q", response.Description)
+	}
+	if _, found := g.definitions[reflect.TypeOf(PriceEvent{})]; !found {
+		t.Fatal("event definition should be added")
+	}
+}
+
+func TestAddResponseExample(t *testing.T) {
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/people/{id}", Method: "GET", Title: "GetPerson"}
+	b, err := g.SetPathItemWithResponses(info, nil, nil)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	b.Response(http.StatusOK, Person{}, "").
+		AddResponseExample(http.StatusOK, "application/json", map[string]interface{}{"name": "John"})
+
+	response := g.paths["/v1/people/{id}"].Get.Responses["200"]
+	if response.Schema == nil || response.Schema.Ref != "#/definitions/Person" {
+		t.Fatalf("unexpected schema %#v", response.Schema)
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	expected := `{"description":"request success","schema":{"$ref":"#/definitions/Person"},` +
+		`"examples":{"application/json":{"name":"John"}}}`
+	if string(data) != expected {
+		t.Fatalf("unexpected response %s", data)
+	}
+}
+
+func TestAutoContentType(t *testing.T) {
+	g := NewGenerator().SetAutoContentType(true)
+	if err := g.SetPathItem(PathItemInfo{Path: "/v1/people", Method: "POST", Title: "CreatePerson"}, nil, Person{}, Person{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if err := g.SetPathItem(PathItemInfo{Path: "/v1/people/{id}", Method: "DELETE", Title: "DeletePerson"}, nil, nil, nil); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if err := g.SetPathItem(PathItemInfo{Path: "/v1/login", Method: "POST", Title: "Login", BodyForm: true}, nil, Person{}, nil); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	jsonType := []string{"application/json"}
+	op := g.paths["/v1/people"].Post
+	if !reflect.DeepEqual(op.Consumes, jsonType) || !reflect.DeepEqual(op.Produces, jsonType) {
+		t.Fatalf("unexpected consumes %v and produces %v", op.Consumes, op.Produces)
+	}
+
+	op = g.paths["/v1/people/{id}"].Delete
+	if op.Consumes != nil || op.Produces != nil {
+		t.Fatalf("unexpected consumes %v and produces %v of operation without body and response", op.Consumes, op.Produces)
+	}
+
+	op = g.paths["/v1/login"].Post
+	if !reflect.DeepEqual(op.Consumes, []string{"application/x-www-form-urlencoded"}) || op.Produces != nil {
+		t.Fatalf("unexpected consumes %v and produces %v of form operation", op.Consumes, op.Produces)
+	}
+
+	b, err := g.SetPathItemWithResponses(PathItemInfo{Path: "/v1/events", Method: "GET", Title: "Events"}, nil, nil)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	b.EventStream(http.StatusOK, Person{}, "")
+	if op = g.paths["/v1/events"].Get; !reflect.DeepEqual(op.Produces, []string{"text/event-stream"}) {
+		t.Fatalf("unexpected produces %v of event stream", op.Produces)
+	}
+}
+
+func TestStatusDescriptions(t *testing.T) {
+	g := NewGenerator()
+	g.SetStatusDescriptions(map[int]string{http.StatusConflict: "already exists"})
+
+	info := PathItemInfo{Path: "/v1/people", Method: "POST", Title: "CreatePerson", SuccessStatus: http.StatusCreated}
+	b, err := g.SetPathItemWithResponses(info, nil, Person{})
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	b.Response(http.StatusCreated, Person{}, "").
+		Response(http.StatusNotFound, nil, "").
+		Response(http.StatusConflict, nil, "").
+		Response(http.StatusTooManyRequests, nil, "")
+
+	expected := map[string]string{
+		"201": "created",
+		"404": "not found",
+		"409": "already exists",
+		"429": "too many requests",
+	}
+	responses := g.paths["/v1/people"].Post.Responses
+	for status, description := range expected {
+		if responses[status].Description != description {
+			t.Fatalf("unexpected description of %s response %q", status, responses[status].Description)
+		}
+	}
+}
+
+func TestSetPathItemAnonymousResponse(t *testing.T) {
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/people/count", Method: "GET", Title: "CountPeople"}
+	response := struct {
+		Count int    `json:"count"`
+		Owner Person `json:"owner"`
+	}{}
+	if err := g.SetPathItem(info, nil, nil, response); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	schema := g.paths["/v1/people/count"].Get.Responses["200"].Schema
+	if schema.Ref != "" || schema.Type != "object" || len(schema.Properties) != 2 {
+		t.Fatalf("response schema should be inline, got %#v", schema)
+	}
+	if owner := schema.Properties["owner"]; owner.Ref != "#/definitions/Person" {
+		t.Fatalf("unexpected owner property %#v", owner)
+	}
+
+	for name := range g.definitions.GenDefinitions() {
+		if name != "Person" && name != "PersonName" {
+			t.Fatalf("unexpected definition %s", name)
+		}
+	}
+}
+
+func TestSetPathItemBodyForGET(t *testing.T) {
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/people/search", Method: "GET", Title: "SearchPeople"}
+	if err := g.SetPathItem(info, nil, Person{}, []Person{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	if params := g.paths["/v1/people/search"].Get.Parameters; len(params) != 0 {
+		t.Fatalf("unexpected parameters %#v", params)
+	}
+	if warnings := g.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "request body") {
+		t.Fatalf("unexpected warnings %v", warnings)
+	}
+
+	g = NewGenerator().AllowBodyForGET(true)
+	if err := g.SetPathItem(info, nil, Person{}, []Person{}); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	params := g.paths["/v1/people/search"].Get.Parameters
+	if len(params) != 1 || params[0].In != "body" {
+		t.Fatalf("unexpected parameters %#v", params)
+	}
+	if warnings := g.Warnings(); len(warnings) != 0 {
+		t.Fatalf("unexpected warnings %v", warnings)
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	cases := map[string]string{
+		"/v1/people/{id}/files/{name}":               "gorilla/mux without regexp",
+		"/v1/people/{id:[0-9]+}/files/{name}":        "gorilla/mux",
+		"/v1/people/{id:\\d+}/files/{name:[a-z]{3}}": "chi",
+		"/v1/people/:id/files/:name":                 "echo",
+	}
+
+	for path, router := range cases {
+		normalized, params := normalizePath(path)
+		if normalized != "/v1/people/{id}/files/{name}" || !reflect.DeepEqual(params, []string{"id", "name"}) {
+			t.Fatalf("unexpected %s path %s with parameters %v", router, normalized, params)
+		}
+	}
+
+	normalized, params := normalizePath("/static/*")
+	if normalized != "/static/{wildcard}" || !reflect.DeepEqual(params, []string{"wildcard"}) {
+		t.Fatalf("unexpected catch-all path %s with parameters %v", normalized, params)
+	}
+
+	normalized, params = normalizePath("/files/*filepath")
+	if normalized != "/files/{filepath}" || !reflect.DeepEqual(params, []string{"filepath"}) {
+		t.Fatalf("unexpected catch-all path %s with parameters %v", normalized, params)
+	}
+}
+
+func TestSetPathItemEchoPath(t *testing.T) {
+	type FileRequest struct {
+		ID int `path:"id"`
+	}
+
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/people/:id/files/*", Method: "GET", Title: "GetFile"}
+	if err := g.SetPathItem(info, FileRequest{}, nil, nil); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	item, found := g.paths["/v1/people/{id}/files/{wildcard}"]
+	if !found {
+		t.Fatalf("normalized path not found in %v", g.paths)
+	}
+
+	params := item.Get.Parameters
+	if len(params) != 2 || params[0].Name != "id" || params[0].Type != "integer" || params[1].Name != "wildcard" {
+		t.Fatalf("unexpected parameters %#v", params)
+	}
+}
+
+func TestResetPaths(t *testing.T) {
+	TestSetPathItem(t)
+
+	if len(gen.paths) == 0 {
+		t.Fatalf("len of gen.paths must be greater than 0")
+	}
+
+	ResetPaths()
+	if len(gen.paths) != 0 {
+		t.Fatalf("len of gen.paths must be equal to 0")
+	}
+}
+
+//
+// benchmark and parallel testing
+//
+
+func BenchmarkParseDefinitionsParallel(b *testing.B) {
+	var (
+		mu sync.Mutex
+		i  int
+	)
+
+	data := []interface{}{
+		&Person{},
+		&PersonName{},
+		&PreferredWarehouseRequest{},
+	}
+
+	b.SetParallelism(10)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			mu.Lock()
+			i++
+			input := data[i%3]
+			mu.Unlock()
+
+			if _, err := ParseDefinition(input); err != nil {
+				b.Fatalf("%v", err)
+			}
+		}
+	})
+}
+
+func BenchmarkGoType(b *testing.B) {
+	t := reflect.TypeOf(map[string][]*sample.TestSampleStruct{})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		goType(t)
+	}
+}
+
+func BenchmarkGoTypeMemoized(b *testing.B) {
+	g := NewGenerator()
+	t := reflect.TypeOf(map[string][]*sample.TestSampleStruct{})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		g.goType(t)
+	}
+}
+
+func BenchmarkSetPathItem(b *testing.B) {
+	h := &testHandler{}
+
+	infos := []PathItemInfo{
+		{
+			Path:        "/v1/test/handler",
+			Title:       "TestHandler",
+			Description: "This is just a test handler with GET request",
+			Method:      "GET",
+		},
+		{
+			Path:        "/v1/test/handler",
+			Title:       "TestHandler",
+			Description: "This is just a test handler with POST reqest",
+			Method:      "POST",
+		},
+	}
+
+	var (
+		mu sync.Mutex
+		i  int
+	)
+
+	b.SetParallelism(10)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			mu.Lock()
+			i++
+			info := infos[i%2]
+			mu.Unlock()
+
+			err := SetPathItem(
+				info,
+				h.GetRequestBuffer(info.Method),
+				h.GetBodyBuffer(),
+				h.GetResponseBuffer(info.Method),
+			)
+
+			if err != nil {
+				b.Fatalf("error %v", err)
+			}
+		}
+	})
+}
+
+// testHandler can handle POST and GET request
+type testHandler struct{}
+
+func (th *testHandler) GetName() string {
+	return "TestHandle"
+}
+
+func (th *testHandler) GetDescription() string {
+	return "This handler for test ParsePathItem"
+}
+
+func (th *testHandler) GetVersion() string {
+	return "v1"
+}
+
+func (th *testHandler) GetRoute() string {
+	return "/test/handler"
+}
+
+func (th *testHandler) GetRequestBuffer(_ string) interface{} {
+	return &PersonName{}
+}
+
+func (th *testHandler) GetResponseBuffer(method string) interface{} {
+	if method == "GET" {
+		return nil
+	}
+
+	return &PreferredWarehouseRequest{}
+}
+
+func (th *testHandler) GetBodyBuffer() interface{} {
+	return &Person{}
+}
+
+func (th *testHandler) HandlePost(_ interface{}, _ interface{}) (response interface{}, err error) {
+	// yes, I can handle a POST request
+	return
+}
+
+func (th *testHandler) HandleGet(_ interface{}) (response interface{}, err error) {
+	// yes, I can handle a GET request
+	return
+}
+
+//
+// Test helper
+//
+
+func getReadableJSON(i interface{}, t *testing.T) []byte {
+	data, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		t.Fatalf("error while parsing struct to JSON string: %v", err)
+	}
+
+	return data
+}
+
+func TestSetHoistAnonymous(t *testing.T) {
+	newStatus := func() interface{} {
+		return struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+			Details struct {
+				Field string `json:"field"`
+			} `json:"details"`
+		}{}
+	}
+
+	g := NewGenerator().SetHoistAnonymous(true)
+	if err := g.SetPathItem(PathItemInfo{Path: "/v1/people", Method: "DELETE", Title: "DeletePeople"}, nil, nil, newStatus()); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := g.SetPathItem(PathItemInfo{Path: "/v1/pets", Method: "DELETE", Title: "DeletePets"}, nil, nil, newStatus()); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	definitions := g.Definitions()
+	if len(definitions) != 2 {
+		t.Fatalf("definitions of status and its details expected, %v received", definitions)
+	}
+	if definitions["Inline2"].Properties["details"].Ref != "#/definitions/Inline1" {
+		t.Fatalf("nested anonymous struct should be hoisted first %#v", definitions["Inline2"])
+	}
+
+	people := g.paths["/v1/people"].Delete.Responses["200"].Schema
+	pets := g.paths["/v1/pets"].Delete.Responses["200"].Schema
+	if people.Ref != "#/definitions/Inline2" || pets.Ref != people.Ref {
+		t.Fatalf("responses should reference hoisted definition %#v %#v", people, pets)
+	}
+
+	g = NewGenerator()
+	schema, err := g.ParseDefinition(newStatus())
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if schema.Ref != "" || len(g.Definitions()) != 0 {
+		t.Fatalf("anonymous struct should be inlined by default %#v", schema)
+	}
+}
+
+type pathGeoPoint struct {
+	Raw fmt.Stringer `json:"raw"`
+}
+
+type pathAddress struct {
+	GeoPoint pathGeoPoint `json:"geo_point"`
+}
+
+type pathUser struct {
+	Address *pathAddress `json:"address"`
+}
+
+func TestParseDefinitionPanicFieldPath(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("panic expected for unsupported type of nested field")
+		}
+		if msg := fmt.Sprint(r); !strings.HasPrefix(msg, "pathUser.Address.GeoPoint.Raw: Non-empty interface is not supported") {
+			t.Fatalf("field path expected in panic message, %q received", msg)
+		}
+	}()
+
+	NewGenerator().ParseDefinition(pathUser{})
+}
+
+func TestParseDefinitionRootEnum(t *testing.T) {
+	g := NewGenerator()
+	schema, err := g.ParseDefinition(Flag(""))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if schema.Type != "string" || !reflect.DeepEqual(schema.Enum, []interface{}{Flag("Foo"), Flag("Bar")}) ||
+		!reflect.DeepEqual(schema.EnumVarNames, []string{"Foo", "Bar"}) {
+		t.Fatalf("unexpected schema of enum %#v", schema)
+	}
+
+	info := PathItemInfo{Path: "/v1/flag", Method: "PUT", Title: "SetFlag"}
+	if err := g.SetPathItem(info, nil, Flag(""), nil); err != nil {
+		t.Fatalf("%v", err)
+	}
+	data, err := json.Marshal(g.paths["/v1/flag"].Put.Parameters[0].Schema)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if string(data) != `{"type":"string","enum":["Foo","Bar"],"x-enum-varnames":["Foo","Bar"]}` {
+		t.Fatalf("unexpected schema of body %s", data)
+	}
+}
+
+func TestSetIntWidth(t *testing.T) {
+	type Counter struct {
+		ID    int    `json:"id" query:"id"`
+		Total uint   `json:"total" query:"total"`
+		Small int16  `json:"small" query:"small"`
+		Large uint64 `json:"large" query:"large"`
+	}
+
+	for _, width := range []int{32, 64} {
+		g := NewGenerator().SetIntWidth(width)
+		if _, err := g.ParseDefinition(Counter{}); err != nil {
+			t.Fatalf("%v", err)
+		}
+		typeDef, _ := g.getDefinition(reflect.TypeOf(Counter{}))
+
+		expected := map[string]string{"id": "int32", "total": "int32", "small": "int32", "large": "int64"}
+		if width == 64 {
+			expected["id"] = "int64"
+			expected["total"] = "int64"
+		}
+		for name, format := range expected {
+			if typeDef.Properties[name].Format != format {
+				t.Fatalf("%s property should have %s format with int width %d, %#v received", name, format, width, typeDef.Properties[name])
+			}
+		}
+
+		_, params, err := g.ParseParameter(Counter{})
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		if params[0].Format != expected["id"] {
+			t.Fatalf("id parameter should have %s format with int width %d, %#v received", expected["id"], width, params[0])
+		}
+	}
+}
+
+func TestSetNullablePointers(t *testing.T) {
+	type Team struct {
+		Lead    *Person `json:"lead"`
+		Deputy  Person  `json:"deputy"`
+		Comment *string `json:"comment"`
+	}
+
+	parse := func(g *Generator) map[string]string {
+		if _, err := g.ParseDefinition(Team{}); err != nil {
+			t.Fatalf("%v", err)
+		}
+		typeDef, _ := g.getDefinition(reflect.TypeOf(Team{}))
+		properties := make(map[string]string)
+		for name, prop := range typeDef.Properties {
+			data, err := json.Marshal(prop)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			properties[name] = string(data)
+		}
+		return properties
+	}
+
+	expected := map[string]string{
+		"lead":    `{"$ref":"#/definitions/Person"}`,
+		"deputy":  `{"$ref":"#/definitions/Person"}`,
+		"comment": `{"type":"string"}`,
+	}
+	if properties := parse(NewGenerator()); !reflect.DeepEqual(properties, expected) {
+		t.Fatalf("unexpected properties without nullable pointers %v", properties)
+	}
+
+	expected = map[string]string{
+		"lead":    `{"allOf":[{"$ref":"#/definitions/Person"}],"x-nullable":true}`,
+		"deputy":  `{"$ref":"#/definitions/Person"}`,
+		"comment": `{"type":"string","x-nullable":true}`,
+	}
+	if properties := parse(NewGenerator().SetNullablePointers(true)); !reflect.DeepEqual(properties, expected) {
+		t.Fatalf("unexpected properties with nullable pointers %v", properties)
+	}
+}
+
+func TestSetFieldTag(t *testing.T) {
+	type Record struct {
+		ID      int    `bson:"_id"`
+		Name    string `bson:"name,omitempty"`
+		Secret  string `bson:"-" json:"secret"`
+		Comment string `json:"comment"`
+		Counter int    `bson:",omitempty"`
+	}
+
+	g := NewGenerator().SetFieldTag("bson")
+	if _, err := g.ParseDefinition(Record{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Record{}))
+
+	var names []string
+	for name := range typeDef.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"Counter", "_id", "name"}) {
+		t.Fatalf("unexpected properties %v", names)
+	}
+}
+
+type externalMoney struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func TestMapTypeToRef(t *testing.T) {
+	type Order struct {
+		Total    externalMoney             `json:"total"`
+		Discount *externalMoney            `json:"discount"`
+		Items    []externalMoney           `json:"items"`
+		Fees     map[string]*externalMoney `json:"fees"`
+	}
+
+	const ref = "common.json#/definitions/Money"
+	g := NewGenerator().MapTypeToRef(externalMoney{}, ref)
+	if _, err := g.ParseDefinition(Order{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Order{}))
+	if typeDef.Properties["total"].Ref != ref || typeDef.Properties["discount"].Ref != ref ||
+		typeDef.Properties["items"].Items.Ref != ref || typeDef.Properties["fees"].AdditionalProperties.Ref != ref {
+		t.Fatalf("external reference expected in properties %#v", typeDef.Properties)
+	}
+
+	schema, err := g.ParseDefinition(&externalMoney{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if schema.Ref != ref {
+		t.Fatalf("external reference expected, %q received", schema.Ref)
+	}
+
+	if _, found := g.Definitions()["externalMoney"]; found {
+		t.Fatal("local definition of externally referenced type should not be added")
+	}
+}
+
+func TestSetWarnUnexportedTagged(t *testing.T) {
+	// bson tag is used as go vet reports json tags of unexported fields
+	type Account struct {
+		Login    string `bson:"login"`
+		password string `bson:"password"`
+		salt     string
+		internal string `bson:"-"`
+		_        string `swgen_title:"Account"`
+	}
+
+	for _, enabled := range []bool{false, true} {
+		g := NewGenerator().SetFieldTag("bson").SetWarnUnexportedTagged(enabled)
+		if _, err := g.ParseDefinition(Account{}); err != nil {
+			t.Fatalf("%v", err)
+		}
+
+		typeDef, _ := g.getDefinition(reflect.TypeOf(Account{}))
+		if _, found := typeDef.Properties["password"]; found || len(typeDef.Properties) != 1 {
+			t.Fatalf("unexported fields should be skipped %v", typeDef.Properties)
+		}
+
+		warnings := g.Warnings()
+		if !enabled && len(warnings) != 0 {
+			t.Fatalf("no warnings expected when disabled, %v received", warnings)
+		}
+		if enabled && (len(warnings) != 1 || !strings.Contains(warnings[0], "Account.password")) {
+			t.Fatalf("warning about password field expected, %v received", warnings)
+		}
+	}
+}
+
+func TestRegisterParameterSet(t *testing.T) {
+	type PageFilter struct {
+		Page    int    `schema:"page"`
+		PerPage int    `schema:"per_page"`
+		Sort    string `schema:"sort"`
+	}
+	type PersonRequest struct {
+		ID int `path:"id"`
+	}
+
+	g := NewGenerator()
+	if err := g.RegisterParameterSet("page", PageFilter{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	people := PathItemInfo{Path: "/v1/people", Method: "GET", Title: "ListPeople", ParameterSet: "page"}
+	if err := g.SetPathItem(people, nil, nil, []Person{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	friends := PathItemInfo{Path: "/v1/people/{id}/friends", Method: "GET", Title: "ListFriends", ParameterSet: "page"}
+	if err := g.SetPathItem(friends, PersonRequest{}, nil, []Person{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var names []string
+	for _, param := range g.paths["/v1/people"].Get.Parameters {
+		names = append(names, param.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"page", "per_page", "sort"}) {
+		t.Fatalf("unexpected parameters of people %v", names)
+	}
+
+	names = nil
+	for _, param := range g.paths["/v1/people/{id}/friends"].Get.Parameters {
+		names = append(names, param.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"id", "page", "per_page", "sort"}) {
+		t.Fatalf("unexpected parameters of friends %v", names)
+	}
+
+	people.Path = "/v2/people"
+	people.ParameterSet = "cursor"
+	if err := g.SetPathItem(people, nil, nil, []Person{}); err == nil || !strings.Contains(err.Error(), "cursor") {
+		t.Fatalf("error expected for undefined parameter set, %v received", err)
+	}
+}
+
+func TestGoTypeMemoized(t *testing.T) {
+	g := NewGenerator()
+	types := []reflect.Type{
+		reflect.TypeOf(Person{}),
+		reflect.TypeOf(&sample.TestSampleStruct{}),
+		reflect.TypeOf(map[string][]*sample.TestSampleStruct{}),
+		reflect.TypeOf(struct{ A int }{}),
+	}
+
+	for i := 0; i < 2; i++ {
+		for _, typ := range types {
+			if memoized, direct := g.goType(typ), goType(typ); memoized != direct {
+				t.Fatalf("memoized go type %q differs from %q", memoized, direct)
+			}
+		}
+	}
+	if len(g.goTypes) != len(types) {
+		t.Fatalf("%d memoized go types expected, %d found", len(types), len(g.goTypes))
+	}
+}
+
+type mappedID struct {
+	hi, lo uint64
+}
+
+func TestMapTypeFormat(t *testing.T) {
+	type Owner struct {
+		ID   mappedID `json:"id"`
+		Name string   `json:"name"`
+	}
+	type Item struct {
+		ID      *mappedID  `json:"id" schema:"id"`
+		OwnerID mappedID   `json:"owner_id"`
+		Related []mappedID `json:"related"`
+		Owner   Owner      `json:"owner"`
+	}
+
+	g := NewGenerator().MapTypeFormat(reflect.TypeOf(mappedID{}), "string", "uuid")
+	if _, err := g.ParseDefinition(Item{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	definitions := g.Definitions()
+	if _, found := definitions["mappedID"]; found || len(definitions) != 2 {
+		t.Fatalf("definitions of Item and Owner expected, %v received", definitions)
+	}
+
+	item := definitions["Item"]
+	schemas := []SchemaObj{item.Properties["id"], item.Properties["owner_id"], *item.Properties["related"].Items,
+		definitions["Owner"].Properties["id"]}
+	for _, schema := range schemas {
+		if schema.Type != "string" || schema.Format != "uuid" || schema.Ref != "" {
+			t.Fatalf("string schema with uuid format expected, %#v received", schema)
+		}
+	}
+
+	_, params, err := g.ParseParameter(Item{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if params[0].Type != "string" || params[0].Format != "uuid" {
+		t.Fatalf("string parameter with uuid format expected, %#v received", params[0])
+	}
+}
+
+func TestReflectGoTypesPackage(t *testing.T) {
+	type Schedule struct {
+		ID      testUUID      `json:"id" query:"id"`
+		Flags   []*Flag       `json:"flags" query:"flags"`
+		Start   time.Time     `json:"start" query:"start"`
+		Timeout time.Duration `json:"timeout" query:"timeout"`
+		Name    string        `json:"name" query:"name"`
+	}
+
+	g := NewGenerator().ReflectGoTypes(true)
+	if _, err := g.ParseDefinition(Schedule{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Schedule{}))
+
+	for _, name := range []string{"id", "flags"} {
+		prop := typeDef.Properties[name]
+		if prop.GoPackage != "github.com/lazada/swgen" || !strings.Contains(prop.GoType, prop.GoPackage+".") {
+			t.Fatalf("inconsistent go type %q and package %q of %s", prop.GoType, prop.GoPackage, name)
+		}
+	}
+	// packages of predeclared and standard library types are omitted
+	for _, name := range []string{"start", "timeout", "name"} {
+		if prop := typeDef.Properties[name]; prop.GoPackage != "" {
+			t.Fatalf("no package expected for %s, %q received", name, prop.GoPackage)
+		}
+	}
+
+	_, params, err := g.ParseParameter(Schedule{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	for _, param := range params {
+		data, err := json.Marshal(param)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		var ext struct {
+			GoType    string `json:"x-go-type"`
+			GoPackage string `json:"x-go-package"`
+		}
+		if err := json.Unmarshal(data, &ext); err != nil {
+			t.Fatalf("%v", err)
+		}
+		if ext.GoPackage != typeDef.Properties[param.Name].GoPackage || ext.GoType != typeDef.Properties[param.Name].GoType {
+			t.Fatalf("go type %q and package %q of %s parameter differ from property", ext.GoType, ext.GoPackage, param.Name)
+		}
+	}
+}
+
+func TestIsStandardPackage(t *testing.T) {
+	expected := map[string]bool{
+		"encoding/json":           true,
+		"net/http/internal":       true,
+		"myapp/models":            false,
+		"github.com/lazada/swgen": false,
+	}
+	for pkgPath, standard := range expected {
+		if isStandardPackage(pkgPath) != standard {
+			t.Fatalf("unexpected result for %s, %v expected", pkgPath, standard)
+		}
+	}
+}
+
+func TestParseDefinitionUnsupportedFields(t *testing.T) {
+	type Worker struct {
+		Name    string            `json:"name" query:"name"`
+		Jobs    chan int          `json:"jobs" query:"jobs"`
+		Handler func(int) error   `json:"handler"`
+		Phases  []complex128      `json:"phases"`
+		Hooks   map[string]func() `json:"hooks"`
+		Raw     unsafe.Pointer    `json:"raw"`
+		Load    float64           `json:"load" query:"load"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Worker{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Worker{}))
+	if len(typeDef.Properties) != 2 || typeDef.Properties["name"].Type != "string" || typeDef.Properties["load"].Type != "number" {
+		t.Fatalf("only name and load properties expected %v", typeDef.Properties)
+	}
+	if warnings := g.Warnings(); len(warnings) != 5 || !strings.Contains(warnings[0], "Worker.Jobs") {
+		t.Fatalf("warnings about unsupported fields expected, %v received", warnings)
+	}
+
+	_, params, err := g.ParseParameter(Worker{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if len(params) != 2 || params[0].Name != "name" || params[1].Name != "load" {
+		t.Fatalf("only name and load parameters expected %v", params)
+	}
+}
+
+func TestSetPathItemBodyForm(t *testing.T) {
+	type LoginForm struct {
+		Login    string   `form:"login" binding:"required"`
+		Password string   `form:"password" binding:"required"`
+		Scopes   []string `form:"scope"`
+	}
+
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/login", Method: "POST", Title: "Login", BodyForm: true}
+	if err := g.SetPathItem(info, nil, LoginForm{}, nil); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	op := g.paths["/v1/login"].Post
+	if !reflect.DeepEqual(op.Consumes, []string{"application/x-www-form-urlencoded"}) {
+		t.Fatalf("unexpected consumes %v", op.Consumes)
+	}
+	if len(op.Parameters) != 3 {
+		t.Fatalf("three form parameters expected %#v", op.Parameters)
+	}
+	for _, param := range op.Parameters {
+		if param.In != "formData" || param.Schema != nil {
+			t.Fatalf("form parameter expected %#v", param)
+		}
+	}
+	if !op.Parameters[0].Required || op.Parameters[2].CollectionFormat != "multi" {
+		t.Fatalf("unexpected form parameters %#v", op.Parameters)
+	}
+	if _, found := g.Definitions()["LoginForm"]; found {
+		t.Fatal("definition of form body should not be added")
+	}
+}
+
+func TestSetReflectGoNamesAndTypes(t *testing.T) {
+	type Filter struct {
+		Query string `json:"query" query:"q"`
+	}
+
+	for _, c := range []struct{ names, types bool }{{false, false}, {true, false}, {false, true}, {true, true}} {
+		g := NewGenerator().SetReflectGoNames(c.names).SetReflectGoTypes(c.types)
+		if _, err := g.ParseDefinition(Filter{}); err != nil {
+			t.Fatalf("%v", err)
+		}
+		_, params, err := g.ParseParameter(Filter{})
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+
+		definition, err := json.Marshal(g.Definitions()["Filter"])
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		param, err := json.Marshal(params[0])
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+
+		for _, ext := range []struct {
+			data     []byte
+			name     string
+			expected bool
+		}{
+			{definition, "x-go-property-names", c.names},
+			{definition, "x-go-property-types", c.types},
+			{definition, "x-go-type", c.types},
+			{param, "x-go-name", c.names},
+			{param, "x-go-type", c.types},
+		} {
+			if strings.Contains(string(ext.data), `"`+ext.name+`"`) != ext.expected {
+				t.Fatalf("%s presence should be %v with names %v and types %v: %s", ext.name, ext.expected, c.names, c.types, ext.data)
+			}
+		}
+	}
+
+	g := NewGenerator().ReflectGoTypes(true)
+	if !g.reflectGoNames || !g.reflectGoTypes {
+		t.Fatal("ReflectGoTypes should enable both names and types")
+	}
+}
+
+type (
+	namedInt     int
+	namedInt8    int8
+	namedInt16   int16
+	namedInt32   int32
+	namedInt64   int64
+	namedUint    uint
+	namedUint8   uint8
+	namedUint16  uint16
+	namedUint32  uint32
+	namedUint64  uint64
+	namedUintptr uintptr
+)
+
+func TestGenSchemaForNamedIntegerTypes(t *testing.T) {
+	for _, c := range []struct {
+		value  interface{}
+		format string
+	}{
+		{namedInt(0), "int32"},
+		{namedInt8(0), "int32"},
+		{namedInt16(0), "int32"},
+		{namedInt32(0), "int32"},
+		{namedInt64(0), "int64"},
+		{namedUint(0), "int32"},
+		{namedUint8(0), "int32"},
+		{namedUint16(0), "int32"},
+		{namedUint32(0), "int64"},
+		{namedUint64(0), "int64"},
+		{namedUintptr(0), "int64"},
+	} {
+		schema := NewGenerator().genSchemaForType(reflect.TypeOf(c.value))
+		if schema.Type != "integer" || schema.Format != c.format {
+			t.Fatalf("%T should be integer with %s format, %s with %s format received", c.value, c.format, schema.Type, schema.Format)
+		}
+	}
+}
+
+func TestRemovePath(t *testing.T) {
+	g := NewGenerator()
+	for _, method := range []string{"GET", "DELETE"} {
+		info := PathItemInfo{Path: "/v1/people/:id", Method: method, Title: method + "Person"}
+		if err := g.SetPathItem(info, nil, nil, Person{}); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+	if err := g.SetPathItem(PathItemInfo{Path: "/v1/people", Method: "GET", Title: "ListPeople"}, nil, nil, []Person{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	g.RemovePathMethod("/v1/people/:id", "delete")
+	item, found := g.paths["/v1/people/{id}"]
+	if !found || item.Delete != nil || item.Get == nil {
+		t.Fatalf("only DELETE operation should be removed %#v", item)
+	}
+
+	g.RemovePathMethod("/v1/people/{id}", "GET")
+	if _, found := g.paths["/v1/people/{id}"]; found {
+		t.Fatal("path item without operations should be removed")
+	}
+
+	g.RemovePath("/v1/people")
+	if len(g.paths) != 0 {
+		t.Fatalf("all paths should be removed %v", g.paths)
+	}
+}
+
+func TestDuplicateParameters(t *testing.T) {
+	type PageFilter struct {
+		Page int `schema:"page" description:"page from parameter set"`
+	}
+	type PersonRequest struct {
+		ID   string `schema:"id"`
+		Page int    `schema:"page" description:"page from request"`
+	}
+
+	g := NewGenerator()
+	if err := g.RegisterParameterSet("page", PageFilter{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	info := PathItemInfo{Path: "/v1/people/{id}/friends", Method: "GET", Title: "ListFriends", ParameterSet: "page"}
+	if err := g.SetPathItem(info, PersonRequest{}, nil, []Person{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var params []string
+	for _, param := range g.paths["/v1/people/{id}/friends"].Get.Parameters {
+		params = append(params, param.In+" "+param.Name+" "+param.Description)
+	}
+	expected := []string{"path id ", "query id ", "query page page from parameter set"}
+	if !reflect.DeepEqual(params, expected) {
+		t.Fatalf("unexpected parameters %q", params)
+	}
+	if warnings := g.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], `duplicate query parameter "page"`) {
+		t.Fatalf("unexpected warnings %v", warnings)
+	}
+
+	g.SetStrictParameters(true)
+	info.Path = "/v2/people/{id}/friends"
+	if err := g.SetPathItem(info, PersonRequest{}, nil, []Person{}); err == nil || !strings.Contains(err.Error(), `duplicate query parameter "page"`) {
+		t.Fatalf("duplicate parameter error expected, %v received", err)
+	}
+}
+
+type Headers map[string]string
+
+func TestNamedMapDefinition(t *testing.T) {
+	type Request struct {
+		Headers Headers           `json:"headers"`
+		Trailer *Headers          `json:"trailer"`
+		Labels  map[string]string `json:"labels"`
+	}
+	type Response struct {
+		Headers Headers `json:"headers"`
+	}
+
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/proxy", Method: "POST", Title: "Proxy"}
+	if err := g.SetPathItem(info, nil, Request{}, Response{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	definitions := g.Definitions()
+	headers, found := definitions["Headers"]
+	if !found || headers.Type != "object" || headers.AdditionalProperties == nil || headers.AdditionalProperties.Type != "string" {
+		t.Fatalf("unexpected definition of named map %#v", headers)
+	}
+
+	request := definitions["Request"]
+	if ref := request.Properties["headers"].Ref; ref != "#/definitions/Headers" {
+		t.Fatalf("unexpected reference %q", ref)
+	}
+	if ref := request.Properties["trailer"].Ref; ref != "#/definitions/Headers" {
+		t.Fatalf("unexpected reference of pointer %q", ref)
+	}
+	if labels := request.Properties["labels"]; labels.Ref != "" || labels.Type != "object" {
+		t.Fatalf("unnamed map should be inlined %#v", labels)
+	}
+	if ref := definitions["Response"].Properties["headers"].Ref; ref != "#/definitions/Headers" {
+		t.Fatalf("unexpected reference %q", ref)
+	}
+}
+
+func TestResponseWrapper(t *testing.T) {
+	type Meta struct {
+		RequestID string `json:"request_id"`
+	}
+
+	g := NewGenerator()
+	meta, err := g.ParseDefinition(Meta{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	g.SetResponseWrapper(func(inner SchemaObj) SchemaObj {
+		return SchemaObj{
+			Type:       "object",
+			Properties: map[string]SchemaObj{"data": inner, "meta": meta},
+			Required:   []string{"data"},
+		}
+	})
+
+	info := PathItemInfo{Path: "/v1/people/{id}", Method: "GET", Title: "GetPerson"}
+	if err := g.SetPathItem(info, nil, nil, Person{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	schema := g.paths["/v1/people/{id}"].Get.Responses["200"].Schema
+	if schema == nil || schema.Type != "object" || !reflect.DeepEqual(schema.Required, []string{"data"}) {
+		t.Fatalf("unexpected envelope %#v", schema)
+	}
+	if ref := schema.Properties["data"].Ref; ref != "#/definitions/Person" {
+		t.Fatalf("unexpected data reference %q", ref)
+	}
+	if ref := schema.Properties["meta"].Ref; ref != "#/definitions/Meta" {
+		t.Fatalf("unexpected meta reference %q", ref)
+	}
+	if _, found := g.definitions[reflect.TypeOf(Person{})]; !found {
+		t.Fatal("definition of wrapped response should be added")
+	}
+}
+
+func TestParseParameterAllowEmptyValue(t *testing.T) {
+	type SearchRequest struct {
+		ID      int    `path:"id" allowEmptyValue:"true"`
+		Verbose bool   `schema:"verbose" allowEmptyValue:"true"`
+		Query   string `schema:"q"`
+	}
+
+	g := NewGenerator()
+	_, params, err := g.ParseParameter(SearchRequest{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	allowEmpty := make(map[string]bool)
+	for _, param := range params {
+		allowEmpty[param.In+" "+param.Name] = param.AllowEmptyValue
+	}
+	expected := map[string]bool{"path id": false, "query verbose": true, "query q": false}
+	if !reflect.DeepEqual(allowEmpty, expected) {
+		t.Fatalf("unexpected allowEmptyValue of parameters %v", allowEmpty)
+	}
+}
+
+func TestInferRequired(t *testing.T) {
+	type Profile struct {
+		Name     string  `json:"name"`
+		Nickname string  `json:"nickname,omitempty"`
+		Email    string  `json:"email,omitempty" required:"true"`
+		Bio      *string `json:"bio" required:"false"`
+	}
+	type Account struct {
+		Profile
+		Login string `json:"login"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Account{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Account{}))
+	if !reflect.DeepEqual(typeDef.Required, []string{"email"}) {
+		t.Fatalf("only explicitly required property expected without inference, got %v", typeDef.Required)
+	}
+
+	g = NewGenerator().SetInferRequired(true)
+	if _, err := g.ParseDefinition(Account{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	typeDef, _ = g.getDefinition(reflect.TypeOf(Account{}))
+	if !reflect.DeepEqual(typeDef.Required, []string{"name", "email", "login"}) {
+		t.Fatalf("unexpected required properties %v", typeDef.Required)
+	}
+}
+
+func TestParameterOrder(t *testing.T) {
+	type UpdateRequest struct {
+		Token   string `schema:"token" in:"header"`
+		DryRun  bool   `schema:"dry_run"`
+		Version int    `path:"version"`
+		Trace   string `schema:"trace" in:"header"`
+		Fields  string `schema:"fields"`
+	}
+
+	g := NewGenerator()
+	g.AddGlobalParameter("Locale", ParamObj{Name: "locale", In: "query", Type: "string"})
+	info := PathItemInfo{Path: "/v{version}/people/{id}", Method: "PUT", Title: "UpdatePerson", Parameters: []string{"Locale"}}
+	if err := g.SetPathItem(info, UpdateRequest{}, Person{}, Person{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var params []string
+	for _, param := range g.paths["/v{version}/people/{id}"].Put.Parameters {
+		if param.Ref != "" {
+			params = append(params, param.Ref)
+		} else {
+			params = append(params, param.In+" "+param.Name)
+		}
+	}
+	expected := []string{
+		"path version", "path id",
+		"query dry_run", "query fields", "#/parameters/Locale",
+		"header token", "header trace",
+		"body body",
+	}
+	if !reflect.DeepEqual(params, expected) {
+		t.Fatalf("unexpected order of parameters %q", params)
+	}
+}
+
+func TestExtraParameters(t *testing.T) {
+	type ListRequest struct {
+		Filter string `schema:"filter"`
+		Limit  int    `schema:"limit"`
+	}
+
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/people", Method: "GET", Title: "ListPeople", ExtraParameters: []ParamObj{
+		{Name: "X-Request-ID", In: "header", Type: "string", Format: "uuid", Required: true},
+		{Name: "limit", In: "query", Type: "integer", Format: "int32", Description: "page size"},
+	}}
+	if err := g.SetPathItem(info, ListRequest{}, nil, []Person{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	params := g.paths["/v1/people"].Get.Parameters
+	if len(params) != 3 {
+		t.Fatalf("unexpected parameters %#v", params)
+	}
+	if params[0].Name != "filter" {
+		t.Fatalf("unexpected first parameter %#v", params[0])
+	}
+	if params[1].Name != "limit" || params[1].Description != "page size" {
+		t.Fatalf("parsed parameter should be replaced by extra one, got %#v", params[1])
+	}
+	if params[2].Name != "X-Request-ID" || params[2].In != "header" || params[2].Format != "uuid" {
+		t.Fatalf("unexpected extra parameter %#v", params[2])
+	}
+	if len(g.Warnings()) != 1 {
+		t.Fatalf("duplicate parameter warning expected, got %v", g.Warnings())
+	}
+}
+
+type unexportedOnly struct {
+	name  string
+	email string
+}
+
+func TestEmptyDefinitionWarning(t *testing.T) {
+	type Contact struct {
+		Owner unexportedOnly `json:"owner"`
+		Phone string         `json:"phone"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Contact{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	warnings := g.Warnings()
+	if len(warnings) != 1 || warnings[0] != "swgen.unexportedOnly: definition unexportedOnly has no properties" {
+		t.Fatalf("unexpected warnings %q", warnings)
+	}
+}
+
+type Labeler interface {
+	Label() string
+}
+
+type textLabel struct {
+	Text string `json:"text"`
+}
+
+func (l textLabel) Label() string { return l.Text }
+
+func TestEmbeddedInterface(t *testing.T) {
+	type Sticker struct {
+		fmt.Stringer
+		Labeler `json:"label"`
+		Name    string `json:"name"`
+	}
+
+	g := NewGenerator()
+	g.RegisterInterfaceImplementations((*Labeler)(nil), textLabel{})
+	if _, err := g.ParseDefinition(Sticker{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(Sticker{}))
+	if len(typeDef.Properties) != 2 || typeDef.Properties["name"].Type != "string" {
+		t.Fatalf("unexpected properties %#v", typeDef.Properties)
+	}
+	if ref := typeDef.Properties["label"].Ref; ref != "#/definitions/Labeler" {
+		t.Fatalf("unexpected reference of embedded interface %q", ref)
+	}
+
+	warnings := g.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "Sticker.Stringer: embedded interface without registered implementations skipped") {
+		t.Fatalf("unexpected warnings %q", warnings)
+	}
+}
+
+func TestEmitPropertyOrder(t *testing.T) {
+	type Audit struct {
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+	type Article struct {
+		Title string `json:"title"`
+		Audit
+		Body   string   `json:"body"`
+		Hidden string   `json:"-"`
+		Tags   []string `json:"tags"`
+	}
+
+	g := NewGenerator().SetEmitPropertyOrder(true)
+	if _, err := g.ParseDefinition(Article{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	data, err := json.Marshal(g.Definitions()["Article"])
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	var article struct {
+		Properties map[string]struct {
+			Order int `json:"x-order"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &article); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	order := make(map[string]int, len(article.Properties))
+	for name, property := range article.Properties {
+		order[name] = property.Order
+	}
+	expected := map[string]int{"title": 0, "created_at": 1, "updated_at": 2, "body": 3, "tags": 4}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("unexpected order of properties %v", order)
+	}
+
+	g = NewGenerator()
+	if _, err := g.ParseDefinition(Article{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if data, _ := json.Marshal(g.Definitions()["Article"]); strings.Contains(string(data), "x-order") {
+		t.Fatalf("x-order should not be added by default %s", data)
+	}
+}
+
+type generatedUser struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
+	Note  string `json:"note"`
+}
+
+func (generatedUser) SwgenRequired() []string {
+	return []string{"id", "email"}
+}
+
+type generatedGroup struct {
+	Name string `json:"name"`
+}
+
+func (*generatedGroup) SwgenRequired() []string {
+	return []string{"name", "owner"}
+}
+
+func TestRequiredInterface(t *testing.T) {
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(generatedUser{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	typeDef, _ := g.getDefinition(reflect.TypeOf(generatedUser{}))
+	if !reflect.DeepEqual(typeDef.Required, []string{"id", "email"}) {
+		t.Fatalf("unexpected required properties %v", typeDef.Required)
+	}
+
+	_, err := g.ParseDefinition(&generatedGroup{})
+	if err == nil || err.Error() != "required property owner of swgen.generatedGroup is not defined" {
+		t.Fatalf("undefined required property error expected, %v received", err)
+	}
+}
+
+func TestNoDefaultResponse(t *testing.T) {
+	g := NewGenerator()
+	g.AddGlobalResponse("NoContent", ResponseObj{Description: "deleted"})
+
+	info := PathItemInfo{Path: "/v1/people/{id}", Method: "DELETE", Title: "DeletePerson", NoDefaultResponse: true,
+		Responses: map[int]string{http.StatusNoContent: "NoContent"}}
+	if err := g.SetPathItem(info, nil, nil, nil); err != nil {
+		t.Fatalf("%v", err)
+	}
+	responses := g.paths["/v1/people/{id}"].Delete.Responses
+	if len(responses) != 1 || responses["204"].Ref != "#/responses/NoContent" {
+		t.Fatalf("unexpected responses %#v", responses)
+	}
+
+	info = PathItemInfo{Path: "/v1/people/{id}", Method: "PUT", Title: "UpdatePerson", NoDefaultResponse: true}
+	b, err := g.SetPathItemWithResponses(info, nil, Person{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	b.Response(http.StatusAccepted, Person{}, "").
+		AddResponseDescription(http.StatusNoContent, "")
+	responses = g.paths["/v1/people/{id}"].Put.Responses
+	if _, found := responses["200"]; found || len(responses) != 2 {
+		t.Fatalf("unexpected responses %#v", responses)
+	}
+}
+
+func TestInvalidDefaultWarning(t *testing.T) {
+	type Settings struct {
+		Retries int  `json:"retries" default:"notanint"`
+		Enabled bool `json:"enabled" default:"true"`
+	}
+	type Account struct {
+		Settings Settings `json:"settings"`
+	}
+	type ListRequest struct {
+		Limit int `query:"limit" default:"ten"`
+	}
+
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(Account{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if _, _, err := g.ParseParameter(ListRequest{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	settings, _ := g.getDefinition(reflect.TypeOf(Settings{}))
+	if settings.Properties["retries"].Default != nil || settings.Properties["enabled"].Default != true {
+		t.Fatalf("unexpected defaults %#v", settings.Properties)
+	}
+
+	warnings := g.Warnings()
+	if len(warnings) != 2 ||
+		!strings.HasPrefix(warnings[0], `Account.Settings.Retries: invalid default "notanint" skipped: `) ||
+		!strings.HasPrefix(warnings[1], `ListRequest.Limit: invalid default "ten" skipped: `) {
+		t.Fatalf("unexpected warnings %q", warnings)
 	}
-
-	return data
 }
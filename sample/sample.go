@@ -1,6 +1,8 @@
 package sample
 
+// TestSampleStruct is a sample structure living in a separate package.
 type TestSampleStruct struct {
+	// SimpleFloat64 is a floating point value.
 	SimpleFloat64 float64 `json:"simple_float64"`
-	SimpleBool    bool    `json:"simple_bool"`
+	SimpleBool    bool    `json:"simple_bool"` // flag value
 }
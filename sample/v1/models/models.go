@@ -0,0 +1,7 @@
+// Package models holds version 1 of sample models that share names with version 2.
+package models
+
+// User is a user of version 1.
+type User struct {
+	Name string `json:"name"`
+}
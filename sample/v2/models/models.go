@@ -0,0 +1,8 @@
+// Package models holds version 2 of sample models that share names with version 1.
+package models
+
+// User is a user of version 2.
+type User struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
@@ -0,0 +1,9 @@
+#!/bin/sh
+# Fetches assets of swagger-ui-dist package of version $1 to static/swagger-ui,
+# they are embedded and served by SwaggerUIHandler.
+set -e
+
+dir="$(dirname "$0")/swagger-ui"
+for file in swagger-ui.css swagger-ui-bundle.js LICENSE; do
+    curl -fsSL "https://unpkg.com/swagger-ui-dist@$1/$file" -o "$dir/$file"
+done
@@ -0,0 +1,89 @@
+//go:build go1.16
+// +build go1.16
+
+package swgen
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+)
+
+// swaggerUIVersion is pinned version of swagger-ui-dist package, its assets are fetched to static/swagger-ui
+// with go generate and embedded, the same version is loaded from CDN if assets were not fetched
+const swaggerUIVersion = "3.52.5"
+
+//go:generate sh static/fetch-swagger-ui.sh 3.52.5
+
+//go:embed static/swagger-ui
+var swaggerUIFiles embed.FS
+
+var (
+	swaggerUIAssets   = mustSubFS(swaggerUIFiles, "static/swagger-ui")
+	swaggerUITemplate = template.Must(template.ParseFS(swaggerUIAssets, "index.html"))
+)
+
+func mustSubFS(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic("could not open embedded " + dir + ": " + err.Error())
+	}
+	return sub
+}
+
+// swaggerUIAssetsURL returns URL prefix of Swagger UI assets, relative URL of embedded assets
+// or URL of pinned version on CDN if assets are not embedded
+func swaggerUIAssetsURL() string {
+	if _, err := fs.Stat(swaggerUIAssets, "swagger-ui-bundle.js"); err == nil {
+		return ""
+	}
+	return "https://unpkg.com/swagger-ui-dist@" + swaggerUIVersion + "/"
+}
+
+// SwaggerUIHandler returns handler serving Swagger UI page that loads specification from specPath,
+// e.g. path of the Generator itself served as http.Handler, and embedded assets of Swagger UI,
+// assets are referenced relative to the page, so handler should be registered with a path ending with slash,
+// e.g. "/docs/", title of the page is API title at the time of request
+func (g *Generator) SwaggerUIHandler(specPath string) http.Handler {
+	assetsURL := swaggerUIAssetsURL()
+	fileServer := http.FileServer(http.FS(swaggerUIAssets))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if name := path.Base(r.URL.Path); name != "index.html" && assetsURL == "" {
+			if _, err := fs.Stat(swaggerUIAssets, name); err == nil {
+				asset := *r
+				asset.URL = new(url.URL)
+				*asset.URL = *r.URL
+				asset.URL.Path = "/" + name
+				fileServer.ServeHTTP(w, &asset)
+				return
+			}
+		}
+
+		g.mu.Lock()
+		title := g.doc.Info.Title
+		g.mu.Unlock()
+		if title == "" {
+			title = "Swagger UI"
+		}
+
+		var page bytes.Buffer
+		data := struct{ Title, AssetsURL, SpecPath string }{title, assetsURL, specPath}
+		if err := swaggerUITemplate.Execute(&page, data); err != nil {
+			http.Error(w, "could not render Swagger UI page: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page.Bytes())
+	})
+}
+
+// SwaggerUIHandler returns handler serving Swagger UI page that loads specification from specPath
+func SwaggerUIHandler(specPath string) http.Handler {
+	return gen.SwaggerUIHandler(specPath)
+}
@@ -0,0 +1,54 @@
+//go:build go1.16
+// +build go1.16
+
+package swgen
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSwaggerUIHandler(t *testing.T) {
+	g := NewGenerator()
+	g.SetInfo("People API", "", "", "")
+	handler := g.SwaggerUIHandler("/docs/swagger.json")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+
+	if contentType := w.Header().Get("Content-Type"); contentType != "text/html; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, `url: "/docs/swagger.json"`) {
+		t.Fatalf("page should load specification from configured path %s", body)
+	}
+	if !strings.Contains(body, "<title>People API</title>") || !strings.Contains(body, "SwaggerUIBundle") {
+		t.Fatalf("unexpected page %s", body)
+	}
+
+	// assets are embedded or loaded from CDN with pinned version
+	assetsURL := swaggerUIAssetsURL()
+	if !strings.Contains(body, `src="`+assetsURL+`swagger-ui-bundle.js"`) {
+		t.Fatalf("unexpected assets in page %s", body)
+	}
+	if assetsURL != "" && !strings.Contains(assetsURL, "swagger-ui-dist@"+swaggerUIVersion+"/") {
+		t.Fatalf("version of assets should be pinned, %s received", assetsURL)
+	}
+	if assetsURL == "" {
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/swagger-ui-bundle.js", nil))
+		if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "<title>") {
+			t.Fatalf("embedded asset expected, %d received", w.Code)
+		}
+	}
+
+	g.SetInfo("Pets API", "", "", "")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+	if !strings.Contains(w.Body.String(), "<title>Pets API</title>") {
+		t.Fatalf("title should be read on request %s", w.Body.String())
+	}
+}
@@ -0,0 +1,227 @@
+package swgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// operationMethods lists HTTP methods of operations in the order they are validated
+var operationMethods = []string{"GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH"}
+
+var regexPathParameter = regexp.MustCompile(`{([^}]+)}`)
+
+// maxSummaryLength is the length of operation summary recommended by specification
+const maxSummaryLength = 120
+
+// Validate checks document with all queued definitions parsed for problems that strict validators reject:
+// undocumented path parameters, duplicate operation ids, undefined security definitions,
+// references to missing definitions and required properties that are not defined,
+// it also reports operation summaries longer than recommended 120 characters
+func (g *Generator) Validate() []error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.prepareDocument(nil); err != nil {
+		return []error{err}
+	}
+
+	v := validator{doc: &g.doc, refPrefix: g.refPrefix}
+
+	var names []string
+	for name := range g.doc.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schema := g.doc.Definitions[name]
+		v.checkSchema("definition "+name, &schema)
+	}
+
+	names = names[:0]
+	for name := range g.doc.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if schema := g.doc.Parameters[name].Schema; schema != nil {
+			v.checkSchema("global parameter "+name, schema)
+		}
+	}
+
+	names = names[:0]
+	for name := range g.doc.Responses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if schema := g.doc.Responses[name].Schema; schema != nil {
+			v.checkSchema("global response "+name, schema)
+		}
+	}
+
+	names = names[:0]
+	for path := range g.doc.Paths {
+		names = append(names, path)
+	}
+	sort.Strings(names)
+	operationIDs := make(map[string]string)
+	for _, path := range names {
+		item := g.doc.Paths[path]
+		for _, method := range operationMethods {
+			if op := item.operation(method); op != nil {
+				v.checkOperation(method+" "+path, path, op, operationIDs)
+			}
+		}
+	}
+
+	return v.errs
+}
+
+// validator collects problems found by Validate
+type validator struct {
+	doc       *Document
+	refPrefix string
+	errs      []error
+}
+
+func (v *validator) errorf(format string, args ...interface{}) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+// checkOperation checks operation and its parameters and responses, operationIDs maps ids to locations of operations
+func (v *validator) checkOperation(location, path string, op *OperationObj, operationIDs map[string]string) {
+	if length := utf8.RuneCountInString(op.Summary); length > maxSummaryLength {
+		v.errorf("%s: summary has %d characters, more than recommended %d", location, length, maxSummaryLength)
+	}
+
+	if op.OperationID != "" {
+		if other, ok := operationIDs[op.OperationID]; ok {
+			v.errorf("%s: operation id %q is already used by %s", location, op.OperationID, other)
+		} else {
+			operationIDs[op.OperationID] = location
+		}
+	}
+
+	for _, requirement := range op.Security {
+		for name := range requirement {
+			if _, ok := v.doc.SecurityDefinitions[name]; !ok {
+				v.errorf("%s: undefined security definition %q", location, name)
+			}
+		}
+	}
+
+	pathParams := make(map[string]bool)
+	for _, param := range op.Parameters {
+		if param.Ref != "" {
+			param = v.doc.Parameters[strings.TrimPrefix(param.Ref, refParameterPrefix)]
+		}
+		if param.In == "path" {
+			pathParams[param.Name] = true
+		}
+		if param.Schema != nil {
+			v.checkSchema(location+" parameter "+param.Name, param.Schema)
+		}
+	}
+	for _, match := range regexPathParameter.FindAllStringSubmatch(path, -1) {
+		if !pathParams[match[1]] {
+			v.errorf("%s: path parameter %q is not documented", location, match[1])
+		}
+	}
+
+	statuses := make([]string, 0, len(op.Responses))
+	for status := range op.Responses {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		if schema := op.Responses[status].Schema; schema != nil {
+			v.checkSchema(location+" response "+status, schema)
+		}
+	}
+}
+
+// checkSchema checks references and required properties of schema and schemas nested in it
+func (v *validator) checkSchema(location string, schema *SchemaObj) {
+	walkSchema(schema, func(s *SchemaObj) {
+		if strings.HasPrefix(s.Ref, v.refPrefix) {
+			if _, ok := v.doc.Definitions[strings.TrimPrefix(s.Ref, v.refPrefix)]; !ok {
+				v.errorf("%s: reference %s to missing definition", location, s.Ref)
+			}
+		}
+		for _, name := range s.Required {
+			if _, ok := s.Properties[name]; !ok {
+				v.errorf("%s: required property %q is not defined", location, name)
+			}
+		}
+	})
+}
+
+// walkSchema calls f for schema and every schema nested in it, references found in raw definition
+// are passed as schemas with only Ref set
+func walkSchema(schema *SchemaObj, f func(s *SchemaObj)) {
+	f(schema)
+	for _, ref := range rawRefs(schema.raw) {
+		f(&SchemaObj{Ref: ref})
+	}
+	if schema.Items != nil {
+		walkSchema(schema.Items, f)
+	}
+	if schema.AdditionalProperties != nil {
+		walkSchema(schema.AdditionalProperties, f)
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property := schema.Properties[name]
+		walkSchema(&property, f)
+	}
+
+	for i := range schema.OneOf {
+		walkSchema(&schema.OneOf[i], f)
+	}
+	for i := range schema.AllOf {
+		walkSchema(&schema.AllOf[i], f)
+	}
+}
+
+// rawRefs returns sorted values of $ref properties found at any depth of raw JSON
+func rawRefs(raw json.RawMessage) []string {
+	if raw == nil {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil
+	}
+
+	var refs []string
+	var collect func(value interface{})
+	collect = func(value interface{}) {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if ref, ok := value["$ref"].(string); ok {
+				refs = append(refs, ref)
+			}
+			for _, v := range value {
+				collect(v)
+			}
+		case []interface{}:
+			for _, v := range value {
+				collect(v)
+			}
+		}
+	}
+	collect(value)
+
+	sort.Strings(refs)
+	return refs
+}
@@ -0,0 +1,129 @@
+package swgen
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// assertValidationErrors checks that Validate reports exactly errors containing expected substrings
+func assertValidationErrors(t *testing.T, g *Generator, expected ...string) {
+	errs := g.Validate()
+	if len(errs) != len(expected) {
+		t.Fatalf("%d validation errors expected, %v received", len(expected), errs)
+	}
+	for i, err := range errs {
+		if !strings.Contains(err.Error(), expected[i]) {
+			t.Fatalf("validation error containing %q expected, %q received", expected[i], err)
+		}
+	}
+}
+
+func TestValidateValid(t *testing.T) {
+	type PersonRequest struct {
+		ID int `path:"id"`
+	}
+
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/people/{id}", Method: "GET", Title: "GetPerson", OperationID: "getPerson"}
+	if err := g.SetPathItem(info, PersonRequest{}, nil, Person{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	assertValidationErrors(t, g)
+}
+
+func TestValidatePathParameters(t *testing.T) {
+	g := NewGenerator()
+	g.paths["/v1/people/{id}"] = PathItem{Get: &OperationObj{Responses: Responses{}}}
+	assertValidationErrors(t, g, `GET /v1/people/{id}: path parameter "id" is not documented`)
+}
+
+func TestValidateOperationIDs(t *testing.T) {
+	g := NewGenerator()
+	for _, path := range []string{"/v1/people", "/v2/people"} {
+		info := PathItemInfo{Path: path, Method: "GET", Title: "ListPeople", OperationID: "listPeople"}
+		if err := g.SetPathItem(info, nil, nil, []Person{}); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+	assertValidationErrors(t, g, `GET /v2/people: operation id "listPeople" is already used by GET /v1/people`)
+}
+
+func TestValidateSecurity(t *testing.T) {
+	g := NewGenerator()
+	g.paths["/v1/people"] = PathItem{Get: &OperationObj{
+		Responses: Responses{},
+		Security:  []map[string][]string{{"Digest": {}}},
+	}}
+	assertValidationErrors(t, g, `GET /v1/people: undefined security definition "Digest"`)
+}
+
+func TestValidateReferences(t *testing.T) {
+	g := NewGenerator()
+	g.AddGlobalResponse("Error", ResponseObj{Description: "error", Schema: &SchemaObj{Ref: "#/definitions/Error"}})
+	g.MapTypeToRef(externalMoney{}, "common.json#/definitions/Money")
+	if _, err := g.ParseDefinition(externalMoney{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	assertValidationErrors(t, g, "global response Error: reference #/definitions/Error to missing definition")
+}
+
+func TestValidateRawDefinitionReferences(t *testing.T) {
+	g := NewGenerator()
+	raw := json.RawMessage(`{"type":"object","properties":{"tags":{"type":"array","items":{"$ref":"#/definitions/Tag"}}}}`)
+	if err := g.AddRawDefinition("Legacy", raw); err != nil {
+		t.Fatalf("%v", err)
+	}
+	assertValidationErrors(t, g, "definition Legacy: reference #/definitions/Tag to missing definition")
+}
+
+type requiredMissingProperty struct{}
+
+func (requiredMissingProperty) SwgenDefinition() (typeName string, typeDef SchemaObj, err error) {
+	return "Account", SchemaObj{
+		Type:       "object",
+		Properties: map[string]SchemaObj{"login": {Type: "string"}},
+		Required:   []string{"login", "password"},
+	}, nil
+}
+
+func TestValidateRequired(t *testing.T) {
+	g := NewGenerator()
+	if _, err := g.ParseDefinition(requiredMissingProperty{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	assertValidationErrors(t, g, `definition Account: required property "password" is not defined`)
+}
+
+func TestValidateSummary(t *testing.T) {
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/people", Method: "GET", Title: strings.Repeat("List people ", 11)}
+	if err := g.SetPathItem(info, nil, nil, []Person{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	assertValidationErrors(t, g, "GET /v1/people: summary has 132 characters, more than recommended 120")
+}
+
+func TestMarkdownDescription(t *testing.T) {
+	description := "Lists people.\n\n## Filtering\n\n* `name` matches **prefix** of \"first_name\"\n* `age` <= 30 & `age` > 18\n\n```json\n{\"page\": 1}\n```\n"
+
+	g := NewGenerator()
+	info := PathItemInfo{Path: "/v1/people", Method: "GET", Title: "ListPeople", Description: description}
+	if err := g.SetPathItem(info, nil, nil, []Person{}); err != nil {
+		t.Fatalf("%v", err)
+	}
+	assertValidationErrors(t, g)
+
+	data, err := g.GenDocument()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if received := doc.Paths["/v1/people"].Get.Description; received != description {
+		t.Fatalf("description %q expected, %q received", description, received)
+	}
+}